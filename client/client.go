@@ -2,11 +2,19 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 type HTTPClient interface {
@@ -14,8 +22,9 @@ type HTTPClient interface {
 }
 
 type Client interface {
-	Read(req Request) (interface{}, error)
-	Write(req Request) (interface{}, error)
+	Read(ctx context.Context, req Request) (interface{}, error)
+	Write(ctx context.Context, req Request) (interface{}, error)
+	Pipeline(ctx context.Context, req Request) ([]Response, error)
 }
 
 type Response struct {
@@ -26,7 +35,9 @@ type Response struct {
 type Request struct {
 	// URL path
 	Path []string
-	// The body sent with the POST request
+	// The body sent with the POST request. A []byte body is sent as is and
+	// appended to the command in Path as its last argument, everything else
+	// is encoded as JSON.
 	Body interface{}
 }
 
@@ -35,6 +46,67 @@ type upstashClient struct {
 	edgeUrl    string
 	httpClient HTTPClient
 	token      string
+	retry      RetryPolicy
+
+	readFromEdge bool
+
+	// The sync token of the latest response, sent with every request when
+	// read-your-writes consistency is enabled
+	readYourWrites bool
+	syncTokenMu    sync.Mutex
+	syncToken      string
+
+	// Source of the retry jitter, seeded per client so processes that fail
+	// together do not retry together
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// Option configures optional behaviour of the client returned by New.
+type Option func(c *upstashClient)
+
+// Send requests with httpClient instead of a default http.Client.
+func WithHTTPClient(httpClient HTTPClient) Option {
+	return func(c *upstashClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// Send reads to the edge url, falling back to the primary url when the edge
+// request fails. Use ForcePrimary to skip the edge for a single call.
+func WithReadFromEdge(readFromEdge bool) Option {
+	return func(c *upstashClient) {
+		c.readFromEdge = readFromEdge
+	}
+}
+
+// Send the sync token of the latest response with every request, so reads
+// from any url see all writes made by this client before.
+func WithReadYourWrites(readYourWrites bool) Option {
+	return func(c *upstashClient) {
+		c.readYourWrites = readYourWrites
+	}
+}
+
+type forcePrimaryKey struct{}
+
+// Returns a context that sends reads made with it to the primary url, even
+// if reading from edge is enabled. Use it when a read must see the latest
+// writes.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return forced
+}
+
+// Retry failed requests according to policy, see RetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *upstashClient) {
+		c.retry = policy
+	}
 }
 
 func New(
@@ -45,90 +117,235 @@ func New(
 	// Requests to the Upstash API must provide an API token.
 	token string,
 
+	// Optional configuration, e.g. WithHTTPClient or WithRetryPolicy
+	options ...Option,
+
 ) Client {
 	httpClient := &http.Client{}
 
-	return &upstashClient{
-		url,
-		edgeUrl,
-		httpClient,
-		token,
+	c := &upstashClient{
+		url:        url,
+		edgeUrl:    edgeUrl,
+		httpClient: httpClient,
+		token:      token,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
 }
 
-// JSON marshal the body if present
-func marshalBody(body interface{}) (io.Reader, error) {
-	var payload io.Reader = nil
-	if body != nil {
-		b, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		payload = bytes.NewBuffer(b)
+// JSON marshal the body if present, raw bodies are sent as is
+func marshalBody(body interface{}) ([]byte, error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return body, nil
 	}
-	return payload, nil
+	return json.Marshal(body)
 }
 
-// Perform a request and return its response
-func (c *upstashClient) request(method string, path []string, body interface{}) (interface{}, error) {
+// Perform a request and decode its response body into response. Reads are
+// sent to the edge url if enabled and fall back to the primary url when the
+// edge fails.
+func (c *upstashClient) request(ctx context.Context, method string, path []string, body interface{}, response interface{}) error {
 	payload, err := marshalBody(body)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to marshal request body: %w", err)
+		return fmt.Errorf("Unable to marshal request body: %w", err)
 	}
 
-	baseUrl := c.url
-	if method == "GET" && c.edgeUrl != "" {
-		baseUrl = c.edgeUrl
+	contentType := "application/json"
+	if _, ok := body.([]byte); ok {
+		contentType = "application/octet-stream"
+	}
+
+	if method == "GET" && c.readFromEdge && c.edgeUrl != "" && !isPrimaryForced(ctx) {
+		_, err := c.do(ctx, method, buildUrl(c.edgeUrl, path), contentType, payload, response)
+		if !shouldFallback(ctx, err) {
+			return err
+		}
 	}
+	return c.send(ctx, method, path, body, contentType, payload, response)
+}
+
+// Returns whether a failed edge read should be retried against the primary
+// url. Errors returned by Redis would be returned by the primary as well.
+func shouldFallback(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var redisErr *RedisError
+	return !errors.As(err, &redisErr)
+}
+
+// Send a request to the primary url. Failed requests are retried according
+// to the retry policy.
+func (c *upstashClient) send(ctx context.Context, method string, path []string, body interface{}, contentType string, payload []byte, response interface{}) error {
+	reqUrl := buildUrl(c.url, path)
+	retry := method == "GET" || c.retry.RetryNonIdempotent || idempotent(path, body)
+	for attempt := 1; ; attempt++ {
+		status, err := c.do(ctx, method, reqUrl, contentType, payload, response)
+		if err == nil || !retry || attempt >= c.retry.MaxAttempts || !c.retry.retryable(ctx, status, err) {
+			return err
+		}
+		if err := sleep(ctx, c.retry.backoff(attempt, c.random())); err != nil {
+			return err
+		}
+	}
+}
 
-	url := fmt.Sprintf("%s/%s", baseUrl, strings.Join(path, "/"))
-	req, err := http.NewRequest(method, url, payload)
+// Returns a random number in [0, 1) for the retry jitter.
+func (c *upstashClient) random() float64 {
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return c.rand.Float64()
+}
+
+func buildUrl(baseUrl string, path []string) string {
+	// Escape every segment, keys and values may contain any character
+	segments := make([]string, len(path))
+	for i, segment := range path {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/%s", baseUrl, strings.Join(segments, "/"))
+}
+
+// Perform a single attempt of a request. Returns the status code of the
+// response, or 0 when no response was received.
+func (c *upstashClient) do(ctx context.Context, method string, reqUrl string, contentType string, payload []byte, response interface{}) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, body)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to create request: %w", err)
+		return 0, fmt.Errorf("Unable to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	// Strings in JSON must be valid UTF-8, let Upstash encode all values so
+	// binary data survives the roundtrip
+	req.Header.Set("Upstash-Encoding", "base64")
+	if c.readYourWrites {
+		if token := c.getSyncToken(); token != "" {
+			req.Header.Set("Upstash-Sync-Token", token)
+		}
+	}
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to perform request: %w", err)
+		return 0, fmt.Errorf("Unable to perform request: %w", err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		var responseBody map[string]interface{}
-		err = json.NewDecoder(res.Body).Decode(&responseBody)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to decode response body of bad response: %s: %w", res.Status, err)
+	if c.readYourWrites {
+		if token := res.Header.Get("Upstash-Sync-Token"); token != "" {
+			c.setSyncToken(token)
 		}
+	}
 
-		// Try to prettyprint the response body
-		// If that is not possible we return the raw body
-		pretty, err := json.MarshalIndent(responseBody, "", "  ")
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, err := ioutil.ReadAll(res.Body)
 		if err != nil {
-			return nil, fmt.Errorf("Response returned status code %d: %+v, path: %s", res.StatusCode, responseBody, path)
+			return res.StatusCode, fmt.Errorf("Unable to read response body of bad response: %s: %w", res.Status, err)
 		}
-		return nil, fmt.Errorf("Response returned status code %d: %+v, path: %s", res.StatusCode, string(pretty), path)
+		return res.StatusCode, newHTTPError(res.StatusCode, body)
+	}
+
+	// Decode numbers as json.Number, float64 can not represent every 64 bit
+	// integer
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+	err = decoder.Decode(response)
+	if err != nil {
+		return res.StatusCode, fmt.Errorf("Unable to unmarshal response: %w", err)
 	}
+	return res.StatusCode, nil
 
+}
+
+// Perform a single command and return its result
+func (c *upstashClient) command(ctx context.Context, method string, path []string, body interface{}) (interface{}, error) {
 	var response Response
-	err = json.NewDecoder(res.Body).Decode(&response)
+	err := c.request(ctx, method, path, body, &response)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to unmarshal response: %w", err)
+		return nil, err
 	}
-	if response.Error != "" {
-		return nil, fmt.Errorf(response.Error)
+	if err := response.Err(); err != nil {
+		return nil, err
 	}
-	return response.Result, nil
-
+	return decodeResult(response.Result)
 }
 
-func (c *upstashClient) Read(req Request) (interface{}, error) {
-	return c.request("GET", req.Path, nil)
+func (c *upstashClient) Read(ctx context.Context, req Request) (interface{}, error) {
+	return c.command(ctx, "GET", req.Path, nil)
 }
 
 // Call the API and unmarshal its response directly
-func (c *upstashClient) Write(req Request) (interface{}, error) {
-	return c.request("POST", req.Path, req.Body)
+func (c *upstashClient) Write(ctx context.Context, req Request) (interface{}, error) {
+	return c.command(ctx, "POST", req.Path, req.Body)
+}
+
+// Send a list of commands in a single request. The path selects the
+// endpoint, e.g. `pipeline`, and the body holds the commands.
+//
+// Returns one response per command, in the same order.
+func (c *upstashClient) Pipeline(ctx context.Context, req Request) ([]Response, error) {
+	var responses []Response
+	err := c.request(ctx, "POST", req.Path, req.Body, &responses)
+	if err != nil {
+		return nil, err
+	}
+	for i := range responses {
+		if responses[i].Result, err = decodeResult(responses[i].Result); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// Decode the base64 encoded strings in a result. Upstash does not encode
+// the "OK" status reply. Integers are returned as int64.
+func decodeResult(result interface{}) (interface{}, error) {
+	switch result := result.(type) {
+	case json.Number:
+		if n, err := result.Int64(); err == nil {
+			return n, nil
+		}
+		return result, nil
+	case string:
+		if result == "OK" {
+			return result, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(result)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to decode base64 result %q: %w", result, err)
+		}
+		return string(decoded), nil
+	case []interface{}:
+		for i, value := range result {
+			decoded, err := decodeResult(value)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = decoded
+		}
+		return result, nil
+	}
+	return result, nil
+}
+
+func (c *upstashClient) getSyncToken() string {
+	c.syncTokenMu.Lock()
+	defer c.syncTokenMu.Unlock()
+	return c.syncToken
+}
+
+func (c *upstashClient) setSyncToken(token string) {
+	c.syncTokenMu.Lock()
+	defer c.syncTokenMu.Unlock()
+	c.syncToken = token
 }
@@ -0,0 +1,459 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chronark/upstash-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+// Starts a server that fails the first failures requests with status and
+// counts all requests.
+func newFlakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(client.Response{Error: "unavailable"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(client.Response{Result: "OK"})
+	}))
+	return srv, &requests
+}
+
+var retryPolicy = client.RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+	Jitter:      0.5,
+}
+
+func TestRetryRead(t *testing.T) {
+	srv, requests := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "OK", res)
+	require.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	srv, requests := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	srv, requests := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func TestRetryStatusCodes(t *testing.T) {
+	srv, requests := newFlakyServer(1, http.StatusBadRequest)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	policy := retryPolicy
+	policy.RetryableStatusCodes = []int{http.StatusBadRequest}
+	srv, requests = newFlakyServer(1, http.StatusBadRequest)
+	defer srv.Close()
+	c = client.New(srv.URL, "", "token", client.WithRetryPolicy(policy))
+
+	_, err = c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestRetryIdempotentWrite(t *testing.T) {
+	srv, requests := newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err := c.Write(context.Background(), client.Request{Body: []string{"set", "key", "value"}})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestRetryNonIdempotentWrite(t *testing.T) {
+	srv, requests := newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err := c.Write(context.Background(), client.Request{Body: []string{"incr", "key"}})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	srv, requests = newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+	c = client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err = c.Pipeline(context.Background(), client.Request{
+		Path: []string{"pipeline"},
+		Body: [][]string{{"set", "key", "1"}, {"incr", "key"}},
+	})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	policy := retryPolicy
+	policy.RetryNonIdempotent = true
+	srv, requests = newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+	c = client.New(srv.URL, "", "token", client.WithRetryPolicy(policy))
+
+	_, err = c.Write(context.Background(), client.Request{Body: []string{"incr", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestRetryConditionalWrite(t *testing.T) {
+	for _, command := range [][]string{
+		{"setnx", "key", "value"},
+		{"hsetnx", "key", "field", "value"},
+		{"msetnx", "a", "1", "b", "2"},
+		{"copy", "a", "b"},
+		{"set", "key", "value", "nx", "px", "1000"},
+		{"set", "key", "value", "xx"},
+		{"set", "key", "value", "get"},
+		{"zadd", "key", "nx", "1", "member"},
+		{"zadd", "key", "ch", "1", "member"},
+		{"zadd", "key", "gt", "1", "member"},
+		{"expire", "key", "10", "nx"},
+		{"pexpire", "key", "10", "xx"},
+		{"expireat", "key", "10", "gt"},
+		{"pexpireat", "key", "10", "lt"},
+	} {
+		srv, requests := newFlakyServer(1, http.StatusBadGateway)
+		defer srv.Close()
+		c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+		_, err := c.Write(context.Background(), client.Request{Body: command})
+		require.Error(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(requests), command[0])
+	}
+
+	srv, requests := newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	_, err := c.Write(context.Background(), client.Request{Body: []string{"copy", "a", "b", "replace"}})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// Close the connection without a response
+			panic(http.ErrAbortHandler)
+		}
+		_ = json.NewEncoder(w).Encode(client.Response{Result: "OK"})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(retryPolicy))
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "OK", res)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv, requests := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer srv.Close()
+	policy := retryPolicy
+	policy.MinBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	c := client.New(srv.URL, "", "token", client.WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Read(ctx, client.Request{Path: []string{"get", "key"}})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func newErrorServer(status int, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	return srv
+}
+
+func TestHTTPError(t *testing.T) {
+	srv := newErrorServer(http.StatusBadGateway, "bad gateway")
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	var httpErr *client.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	require.Equal(t, "bad gateway", string(httpErr.Body))
+
+	var redisErr *client.RedisError
+	require.False(t, errors.As(err, &redisErr))
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := newErrorServer(http.StatusUnauthorized, `{"error":"Unauthorized"}`)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.True(t, errors.Is(err, client.ErrUnauthorized))
+	require.False(t, errors.Is(err, client.ErrRateLimited))
+}
+
+func TestRateLimited(t *testing.T) {
+	srv := newErrorServer(http.StatusTooManyRequests, `{"error":"ERR max requests limit exceeded"}`)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Write(context.Background(), client.Request{Body: []string{"set", "key", "value"}})
+	require.True(t, errors.Is(err, client.ErrRateLimited))
+	require.False(t, errors.Is(err, client.ErrUnauthorized))
+}
+
+func TestRedisError(t *testing.T) {
+	srv := newErrorServer(http.StatusBadRequest, `{"error":"WRONGTYPE Operation against a key holding the wrong kind of value"}`)
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Write(context.Background(), client.Request{Body: []string{"incr", "key"}})
+	var redisErr *client.RedisError
+	require.True(t, errors.As(err, &redisErr))
+	require.Equal(t, "WRONGTYPE", redisErr.Prefix)
+	require.Equal(t, "WRONGTYPE Operation against a key holding the wrong kind of value", redisErr.Message)
+
+	var httpErr *client.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+}
+
+func TestNewRedisError(t *testing.T) {
+	require.Equal(t, "NOSCRIPT", client.NewRedisError("NOSCRIPT No matching script. Please use EVAL.").Prefix)
+	require.Equal(t, "ERR", client.NewRedisError("ERR").Prefix)
+	require.Equal(t, "", client.NewRedisError("Unauthorized").Prefix)
+	require.Equal(t, "", client.NewRedisError("").Prefix)
+}
+
+func TestBase64Encoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "base64", r.Header.Get("Upstash-Encoding"))
+		_ = json.NewEncoder(w).Encode(client.Response{
+			Result: []interface{}{"AP8=", "OK", float64(1), nil, []interface{}{"YQ=="}},
+		})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"mget", "a", "b"}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"\x00\xff", "OK", int64(1), nil, []interface{}{"a"}}, res)
+}
+
+func TestInvalidBase64(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(client.Response{Result: []interface{}{"YQ==", "not base64"}})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"mget", "a", "b"}})
+	require.Error(t, err)
+}
+
+func TestLargeIntegers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"result":9007199254740993},{"result":-9223372036854775808}]`))
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	responses, err := c.Pipeline(context.Background(), client.Request{
+		Path: []string{"pipeline"},
+		Body: [][]string{{"incr", "a"}, {"get", "b"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(9007199254740993), responses[0].Result)
+	require.Equal(t, int64(-9223372036854775808), responses[1].Result)
+}
+
+func TestRawBody(t *testing.T) {
+	value := []byte{0x00, 0xff}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "/set/key", r.URL.Path)
+		require.Equal(t, value, body)
+		_ = json.NewEncoder(w).Encode(client.Response{Result: "OK"})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+
+	res, err := c.Write(context.Background(), client.Request{Path: []string{"set", "key"}, Body: value})
+	require.NoError(t, err)
+	require.Equal(t, "OK", res)
+}
+
+// Starts a server that responds with result and counts all requests.
+func newCountingServer(result interface{}) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_ = json.NewEncoder(w).Encode(client.Response{Result: result})
+	}))
+	return srv, &requests
+}
+
+func TestReadFromEdge(t *testing.T) {
+	primary, primaryRequests := newCountingServer("cHJpbWFyeQ==")
+	defer primary.Close()
+	edge, edgeRequests := newCountingServer("ZWRnZQ==")
+	defer edge.Close()
+	c := client.New(primary.URL, edge.URL, "token", client.WithReadFromEdge(true))
+	ctx := context.Background()
+
+	res, err := c.Read(ctx, client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "edge", res)
+
+	res, err = c.Read(client.ForcePrimary(ctx), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "primary", res)
+
+	_, err = c.Write(ctx, client.Request{Body: []string{"set", "key", "value"}})
+	require.NoError(t, err)
+
+	require.Equal(t, int32(1), atomic.LoadInt32(edgeRequests))
+	require.Equal(t, int32(2), atomic.LoadInt32(primaryRequests))
+}
+
+func TestReadFromEdgeDisabled(t *testing.T) {
+	primary, primaryRequests := newCountingServer("cHJpbWFyeQ==")
+	defer primary.Close()
+	edge, edgeRequests := newCountingServer("ZWRnZQ==")
+	defer edge.Close()
+	c := client.New(primary.URL, edge.URL, "token")
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "primary", res)
+	require.Equal(t, int32(0), atomic.LoadInt32(edgeRequests))
+	require.Equal(t, int32(1), atomic.LoadInt32(primaryRequests))
+}
+
+func TestReadFromEdgeFallback(t *testing.T) {
+	primary, primaryRequests := newCountingServer("cHJpbWFyeQ==")
+	defer primary.Close()
+	edge, edgeRequests := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer edge.Close()
+	c := client.New(primary.URL, edge.URL, "token", client.WithReadFromEdge(true))
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "primary", res)
+	require.Equal(t, int32(1), atomic.LoadInt32(edgeRequests))
+	require.Equal(t, int32(1), atomic.LoadInt32(primaryRequests))
+}
+
+func TestReadFromEdgeTimeout(t *testing.T) {
+	primary, _ := newCountingServer("cHJpbWFyeQ==")
+	defer primary.Close()
+	edge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer edge.Close()
+	c := client.New(primary.URL, edge.URL, "token",
+		client.WithReadFromEdge(true),
+		client.WithHTTPClient(&http.Client{Timeout: 20 * time.Millisecond}),
+	)
+
+	res, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "primary", res)
+}
+
+func TestReadFromEdgeRedisError(t *testing.T) {
+	primary, primaryRequests := newCountingServer("cHJpbWFyeQ==")
+	defer primary.Close()
+	edge := newErrorServer(http.StatusBadRequest, `{"error":"WRONGTYPE Operation against a key holding the wrong kind of value"}`)
+	defer edge.Close()
+	c := client.New(primary.URL, edge.URL, "token", client.WithReadFromEdge(true))
+
+	_, err := c.Read(context.Background(), client.Request{Path: []string{"get", "key"}})
+	var redisErr *client.RedisError
+	require.True(t, errors.As(err, &redisErr))
+	require.Equal(t, int32(0), atomic.LoadInt32(primaryRequests))
+}
+
+func TestReadYourWrites(t *testing.T) {
+	var requests int32
+	tokens := make(chan string, 3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		tokens <- r.Header.Get("Upstash-Sync-Token")
+		w.Header().Set("Upstash-Sync-Token", fmt.Sprintf("token-%d", n))
+		_ = json.NewEncoder(w).Encode(client.Response{Result: "OK"})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token", client.WithReadYourWrites(true))
+	ctx := context.Background()
+
+	_, err := c.Write(ctx, client.Request{Body: []string{"set", "key", "value"}})
+	require.NoError(t, err)
+	require.Equal(t, "", <-tokens)
+
+	_, err = c.Read(ctx, client.Request{Path: []string{"get", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "token-1", <-tokens)
+
+	_, err = c.Write(ctx, client.Request{Body: []string{"del", "key"}})
+	require.NoError(t, err)
+	require.Equal(t, "token-2", <-tokens)
+}
+
+func TestReadYourWritesDisabled(t *testing.T) {
+	tokens := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens <- r.Header.Get("Upstash-Sync-Token")
+		w.Header().Set("Upstash-Sync-Token", "token")
+		_ = json.NewEncoder(w).Encode(client.Response{Result: "OK"})
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, "", "token")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Read(ctx, client.Request{Path: []string{"get", "key"}})
+		require.NoError(t, err)
+		require.Equal(t, "", <-tokens)
+	}
+}
@@ -0,0 +1,282 @@
+// Package clienttest provides a programmable client.Client for unit tests of
+// code that depends on Upstash.
+//
+//	m := clienttest.NewMock()
+//	m.On("get", "key").Return("value")
+//	u := upstash.NewWithClient(m)
+//
+//	value, err := u.Get(ctx, "key")
+//	m.AssertCommands(t, []string{"get", "key"})
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Call is a single request received by the Mock.
+type Call struct {
+	// Read, Write or Pipeline
+	Method string
+
+	Request client.Request
+}
+
+// Returns the commands sent with the call. Reads and writes contain a
+// single command, pipelines one per queued command.
+func (c Call) Commands() [][]string {
+	switch body := c.Request.Body.(type) {
+	case []string:
+		return [][]string{body}
+	case []byte:
+		command := append([]string{}, c.Request.Path...)
+		return [][]string{append(command, string(body))}
+	case [][]string:
+		return body
+	}
+	return [][]string{c.Request.Path}
+}
+
+// Stub is a scripted response for commands matching its arguments, see
+// Mock.On.
+type Stub struct {
+	args   []string
+	result interface{}
+	err    error
+	once   bool
+	used   bool
+}
+
+// Respond with result. Results use the types returned by the default
+// client: string, int64, nil and []interface{}. int and []string are
+// converted for convenience.
+func (s *Stub) Return(result interface{}) *Stub {
+	s.result = normalize(result)
+	return s
+}
+
+// Respond with err. Errors of type *client.RedisError are returned per
+// command in pipelines, any other error fails the whole pipeline.
+func (s *Stub) ReturnError(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// Only use the stub for the first matching command.
+func (s *Stub) Once() *Stub {
+	s.once = true
+	return s
+}
+
+func (s *Stub) matches(command []string) bool {
+	if s.once && s.used {
+		return false
+	}
+	if len(s.args) == 0 {
+		return true
+	}
+	if len(s.args) != len(command) || !strings.EqualFold(s.args[0], command[0]) {
+		return false
+	}
+	return reflect.DeepEqual(s.args[1:], command[1:])
+}
+
+// Mock implements client.Client. It records every request and responds with
+// the first matching stub registered with On. Commands without a matching
+// stub fail with an error.
+//
+// A Mock is safe for concurrent use.
+type Mock struct {
+	mu    sync.Mutex
+	stubs []*Stub
+	calls []Call
+}
+
+var _ client.Client = (*Mock)(nil)
+
+// Creates a new Mock without any stubs.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Registers a response for commands with exactly these arguments, e.g.
+// On("get", "key"). The command name is case insensitive. Without arguments
+// the stub matches every command. Stubs are matched in the order they were
+// registered.
+func (m *Mock) On(args ...string) *Stub {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &Stub{args: args}
+	m.stubs = append(m.stubs, s)
+	return s
+}
+
+// Returns all recorded calls, in the order they were received.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call{}, m.calls...)
+}
+
+// Returns the commands of all recorded calls, in the order they were
+// received. Pipelined commands are included one by one.
+func (m *Mock) Commands() [][]string {
+	commands := [][]string{}
+	for _, call := range m.Calls() {
+		commands = append(commands, call.Commands()...)
+	}
+	return commands
+}
+
+// Removes all stubs and recorded calls.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stubs = nil
+	m.calls = nil
+}
+
+func (m *Mock) Read(ctx context.Context, req client.Request) (interface{}, error) {
+	return m.command(ctx, Call{Method: "Read", Request: req})
+}
+
+func (m *Mock) Write(ctx context.Context, req client.Request) (interface{}, error) {
+	return m.command(ctx, Call{Method: "Write", Request: req})
+}
+
+func (m *Mock) Pipeline(ctx context.Context, req client.Request) ([]client.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	call := Call{Method: "Pipeline", Request: req}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+
+	commands := call.Commands()
+	responses := make([]client.Response, len(commands))
+	for i, command := range commands {
+		result, err := m.respond(command)
+		var redisErr *client.RedisError
+		switch {
+		case errors.As(err, &redisErr):
+			responses[i].Error = redisErr.Message
+		case err != nil:
+			return nil, err
+		default:
+			responses[i].Result = result
+		}
+	}
+	return responses, nil
+}
+
+func (m *Mock) command(ctx context.Context, call Call) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+	return m.respond(call.Commands()[0])
+}
+
+// Returns the response of the first stub matching command. The caller must
+// hold m.mu.
+func (m *Mock) respond(command []string) (interface{}, error) {
+	for _, s := range m.stubs {
+		if s.matches(command) {
+			s.used = true
+			return s.result, s.err
+		}
+	}
+	return nil, fmt.Errorf("Unexpected command: %v", command)
+}
+
+// Reports an error unless exactly these commands were received, in this
+// order.
+func (m *Mock) AssertCommands(t testing.TB, expected ...[]string) bool {
+	t.Helper()
+	commands := m.Commands()
+	if len(expected) == 0 {
+		expected = [][]string{}
+	}
+	if !reflect.DeepEqual(expected, commands) {
+		t.Errorf("Unexpected commands:\nexpected: %v\nactual:   %v", expected, commands)
+		return false
+	}
+	return true
+}
+
+// Reports an error unless a command with exactly these arguments was
+// received.
+func (m *Mock) AssertCalled(t testing.TB, args ...string) bool {
+	t.Helper()
+	if !m.called(args) {
+		t.Errorf("Expected command %v, got: %v", args, m.Commands())
+		return false
+	}
+	return true
+}
+
+// Reports an error if a command with exactly these arguments was received.
+func (m *Mock) AssertNotCalled(t testing.TB, args ...string) bool {
+	t.Helper()
+	if m.called(args) {
+		t.Errorf("Unexpected command %v", args)
+		return false
+	}
+	return true
+}
+
+// Reports an error for every stub registered with Once that was not used.
+func (m *Mock) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ok := true
+	for _, s := range m.stubs {
+		if s.once && !s.used {
+			t.Errorf("Expected command %v was not received", s.args)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (m *Mock) called(args []string) bool {
+	s := &Stub{args: args}
+	for _, command := range m.Commands() {
+		if s.matches(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert convenience types into the types returned by the default client.
+func normalize(result interface{}) interface{} {
+	switch result := result.(type) {
+	case int:
+		return int64(result)
+	case []string:
+		values := make([]interface{}, len(result))
+		for i, value := range result {
+			values[i] = value
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, len(result))
+		for i, value := range result {
+			values[i] = normalize(value)
+		}
+		return values
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package clienttest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/chronark/upstash-go/client"
+	"github.com/chronark/upstash-go/client/clienttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMock(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On("get", "key").Return("value")
+	m.On("incr", "counter").Return(42)
+	m.On("lrange", "list", "0", "-1").Return([]string{"a", "b"})
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	value, err := u.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	n, err := u.Incr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+
+	list, err := u.LRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, list)
+
+	_, err = u.Get(ctx, "other")
+	require.Error(t, err)
+
+	m.AssertCommands(t,
+		[]string{"get", "key"},
+		[]string{"incr", "counter"},
+		[]string{"lrange", "list", "0", "-1"},
+		[]string{"get", "other"},
+	)
+	m.AssertCalled(t, "incr", "counter")
+	m.AssertNotCalled(t, "del", "key")
+}
+
+func TestMockErrors(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On("get", "key").ReturnError(client.NewRedisError("WRONGTYPE Operation against a key holding the wrong kind of value"))
+	m.On("get", "missing").Return(nil)
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	_, err := u.Get(ctx, "key")
+	var redisErr *client.RedisError
+	require.True(t, errors.As(err, &redisErr))
+	require.Equal(t, "WRONGTYPE", redisErr.Prefix)
+
+	_, err = u.Get(ctx, "missing")
+	require.ErrorIs(t, err, upstash.ErrNil)
+}
+
+func TestMockOnce(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On("incr", "counter").Return(1).Once()
+	m.On("incr", "counter").Return(2).Once()
+	m.On("set").Return("OK").Once()
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	n, err := u.Incr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = u.Incr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	_, err = u.Incr(ctx, "counter")
+	require.Error(t, err)
+
+	mockT := &testing.T{}
+	require.False(t, m.AssertExpectations(mockT))
+	require.True(t, mockT.Failed())
+}
+
+func TestMockPipeline(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On("set", "key", "1").Return("OK")
+	m.On("incr", "key").ReturnError(client.NewRedisError("ERR value is not an integer or out of range"))
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	cmds, err := u.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		_ = p.Set(ctx, "key", "1")
+		_, _ = p.Incr(ctx, "key")
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, cmds[0].Err())
+	require.Error(t, cmds[1].Err())
+
+	calls := m.Calls()
+	require.Len(t, calls, 1)
+	require.Equal(t, "Pipeline", calls[0].Method)
+	m.AssertCommands(t, []string{"set", "key", "1"}, []string{"incr", "key"})
+
+	m.Reset()
+	m.AssertCommands(t)
+}
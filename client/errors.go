@@ -0,0 +1,118 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// Matches an *HTTPError with status 401 or 403, e.g. when the token is
+	// missing or invalid.
+	ErrUnauthorized = errors.New("Unauthorized")
+
+	// Matches an *HTTPError with status 429, returned when the request
+	// limit of the database is exceeded.
+	ErrRateLimited = errors.New("Rate limited")
+)
+
+// HTTPError is returned when Upstash responds with a non 2xx status code.
+// Upstash responds with 400 when Redis returned an error for the command,
+// which can be unwrapped as *RedisError.
+//
+//	var httpErr *client.HTTPError
+//	if errors.As(err, &httpErr) {
+//		fmt.Println(httpErr.StatusCode)
+//	}
+type HTTPError struct {
+	StatusCode int
+
+	// The raw response body
+	Body []byte
+
+	// The error message of the body, if it is a JSON error response
+	message  string
+	redisErr *RedisError
+}
+
+func newHTTPError(statusCode int, body []byte) *HTTPError {
+	e := &HTTPError{StatusCode: statusCode, Body: body}
+	var response Response
+	if err := json.Unmarshal(body, &response); err == nil && response.Error != "" {
+		e.message = response.Error
+		if statusCode == http.StatusBadRequest {
+			e.redisErr = NewRedisError(response.Error)
+		}
+	}
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("Response returned status code %d: %s", e.StatusCode, e.message)
+	}
+	return fmt.Sprintf("Response returned status code %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+// Returns the *RedisError contained in the body, if any.
+func (e *HTTPError) Unwrap() error {
+	if e.redisErr == nil {
+		return nil
+	}
+	return e.redisErr
+}
+
+// Reports whether the status code matches ErrUnauthorized or
+// ErrRateLimited.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// RedisError is an error returned by Redis for a command, e.g.
+// "WRONGTYPE Operation against a key holding the wrong kind of value".
+//
+//	var redisErr *client.RedisError
+//	if errors.As(err, &redisErr) && redisErr.Prefix == "WRONGTYPE" {
+//		...
+//	}
+type RedisError struct {
+	// The error code, e.g. ERR, WRONGTYPE or NOSCRIPT. Empty if the message
+	// does not start with an error code.
+	Prefix string
+
+	// The full error message, including the prefix.
+	Message string
+}
+
+// Parse an error message returned by Redis.
+func NewRedisError(message string) *RedisError {
+	prefix := message
+	if i := strings.IndexByte(message, ' '); i >= 0 {
+		prefix = message[:i]
+	}
+	if prefix == "" || strings.ToUpper(prefix) != prefix || strings.ToLower(prefix) == prefix {
+		prefix = ""
+	}
+	return &RedisError{Prefix: prefix, Message: message}
+}
+
+func (e *RedisError) Error() string {
+	return e.Message
+}
+
+// Returns the error of the response as *RedisError, or nil if the command
+// succeeded.
+func (r Response) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return NewRedisError(r.Error)
+}
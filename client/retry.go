@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Status codes retried when RetryPolicy.RetryableStatusCodes is nil.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// A sensible policy for most applications: up to 3 attempts, waiting
+// between 50ms and 1s before retrying.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  50 * time.Millisecond,
+	MaxBackoff:  time.Second,
+	Jitter:      0.5,
+}
+
+// RetryPolicy configures how requests that failed because of a network
+// error or a retryable status code are retried. The zero value disables
+// retries.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// Wait time before the first retry, doubled for every further retry.
+	MinBackoff time.Duration
+
+	// Upper bound for the wait time between retries, unlimited if 0.
+	MaxBackoff time.Duration
+
+	// Reduce every wait time by a random fraction of up to Jitter, between
+	// 0 and 1, so clients failing at the same time do not retry in lockstep.
+	Jitter float64
+
+	// Status codes that are retried, DefaultRetryableStatusCodes if nil.
+	RetryableStatusCodes []int
+
+	// Also retry commands that are not idempotent, e.g. INCR or LPUSH. A
+	// request that failed may still have been executed by Upstash, so the
+	// command might be applied twice.
+	RetryNonIdempotent bool
+}
+
+// Returns whether a request that failed with status and err should be
+// retried. status is 0 when no response was received.
+func (p RetryPolicy) retryable(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if status == 0 {
+		return true
+	}
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	for _, code := range codes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the wait time before the given retry, starting at 1. random is a
+// random number in [0, 1) used for the jitter.
+func (p RetryPolicy) backoff(retry int, random float64) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(random * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Commands that can safely be sent multiple times, because executing them
+// again does not change the outcome. Reads are always idempotent.
+// Conditional writes like SETNX are missing, because a retry after a lost
+// response would reply that the key already exists. The same applies to
+// the arguments in conditionalArguments.
+var idempotentCommands = map[string]bool{
+	"del":       true,
+	"exists":    true,
+	"expire":    true,
+	"expireat":  true,
+	"flushall":  true,
+	"get":       true,
+	"getrange":  true,
+	"hdel":      true,
+	"hset":      true,
+	"lset":      true,
+	"ltrim":     true,
+	"mset":      true,
+	"persist":   true,
+	"pexpire":   true,
+	"pexpireat": true,
+	"psetex":    true,
+	"sadd":      true,
+	"script":    true,
+	"set":       true,
+	"setex":     true,
+	"setrange":  true,
+	"srem":      true,
+	"touch":     true,
+	"unlink":    true,
+	"zadd":      true,
+	"zrem":      true,
+}
+
+// Arguments that make an otherwise idempotent command conditional, or make
+// its reply depend on the previous value. Commands with one of them are not
+// retried.
+var conditionalArguments = map[string][]string{
+	"expire":    {"nx", "xx", "gt", "lt"},
+	"expireat":  {"nx", "xx", "gt", "lt"},
+	"pexpire":   {"nx", "xx", "gt", "lt"},
+	"pexpireat": {"nx", "xx", "gt", "lt"},
+	"set":       {"nx", "xx", "get"},
+	"zadd":      {"nx", "xx", "gt", "lt", "ch", "incr"},
+}
+
+// Returns whether every command in the request is idempotent. body is
+// either a single command, a list of commands or the raw last argument of
+// the command in path.
+func idempotent(path []string, body interface{}) bool {
+	switch body := body.(type) {
+	case []byte:
+		return idempotent(nil, path)
+	case []string:
+		if len(body) == 0 {
+			return false
+		}
+		command := strings.ToLower(body[0])
+		if command == "copy" {
+			// Without REPLACE a retried COPY replies 0, because the
+			// destination already exists
+			for _, arg := range body[1:] {
+				if strings.EqualFold(arg, "replace") {
+					return true
+				}
+			}
+			return false
+		}
+		// Values are checked as well, which only prevents a safe retry
+		for _, arg := range body[1:] {
+			for _, conditional := range conditionalArguments[command] {
+				if strings.EqualFold(arg, conditional) {
+					return false
+				}
+			}
+		}
+		return idempotentCommands[command]
+	case [][]string:
+		for _, command := range body {
+			if !idempotent(nil, command) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Wait for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+
 	"github.com/chronark/upstash-go"
 )
 
@@ -10,7 +12,7 @@ func main() {
 		panic(err)
 	}
 
-	err = u.FlushAll()
+	err = u.FlushAll(context.Background())
 	if err != nil {
 		panic(err)
 	}
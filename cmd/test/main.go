@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/chronark/upstash-go"
 )
@@ -13,15 +14,17 @@ func main() {
 		Token: "", // env: UPSTASH_REDIS_REST_TOKEN
 	}
 
+	ctx := context.Background()
+
 	u, err := upstash.New(options)
 	if err != nil {
 		panic(err)
 	}
-	err = u.Set("foo", "bar")
+	err = u.Set(ctx, "foo", "bar")
 	if err != nil {
 		panic(err)
 	}
-	value, err := u.Get("foo")
+	value, err := u.Get(ctx, "foo")
 	if err != nil {
 		panic(err)
 	}
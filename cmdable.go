@@ -0,0 +1,164 @@
+package upstash
+
+import (
+	"context"
+	"time"
+)
+
+// Cmdable is the set of commands shared by Upstash, Pipeline and Tx. Accept
+// it instead of *Upstash to run the same code directly, queued in a
+// pipeline or against a test double.
+//
+//	func register(ctx context.Context, c upstash.Cmdable, user string) error {
+//		_, err := c.SAdd(ctx, "users", []string{user})
+//		return err
+//	}
+type Cmdable interface {
+	// Keys
+	Copy(ctx context.Context, source string, destination string, replace bool) (int, error)
+	Del(ctx context.Context, keys []string) (int64, error)
+	Exists(ctx context.Context, keys []string) (int64, error)
+	Expire(ctx context.Context, key string, seconds int, options ExpireOptions) (int, error)
+	ExpireAt(ctx context.Context, key string, timestamp time.Time, options ExpireOptions) (int, error)
+	Persist(ctx context.Context, key string) (int, error)
+	PExpire(ctx context.Context, key string, milliseconds int, options ExpireOptions) (int, error)
+	PExpireAt(ctx context.Context, key string, timestamp time.Time, options ExpireOptions) (int, error)
+	PTTL(ctx context.Context, key string) (time.Duration, error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	RandomKey(ctx context.Context) (string, error)
+	Rename(ctx context.Context, key string, newKey string) error
+	RenameNX(ctx context.Context, key string, newKey string) (int, error)
+	Scan(ctx context.Context, cursor uint64, options ScanOptions) (uint64, []string, error)
+	Touch(ctx context.Context, keys []string) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Type(ctx context.Context, key string) (string, error)
+	Unlink(ctx context.Context, keys []string) (int64, error)
+
+	// Strings
+	Append(ctx context.Context, key string, value string) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+	DecrBy(ctx context.Context, key string, decrement int64) (int64, error)
+	Get(ctx context.Context, key string) (string, error)
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	GetDel(ctx context.Context, key string) (string, error)
+	GetEX(ctx context.Context, key string, options GetEXOptions) (string, error)
+	GetRange(ctx context.Context, key string, start int, end int) (string, error)
+	GetSet(ctx context.Context, key string, value string) (string, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, increment int64) (int64, error)
+	IncrByFloat(ctx context.Context, key string, increment float64) (float64, error)
+	MGet(ctx context.Context, keys []string) ([]NullString, error)
+	MSet(ctx context.Context, kvPairs []KV) error
+	MSetNX(ctx context.Context, kvPairs []KV) (int, error)
+	PSetEX(ctx context.Context, key string, milliseconds int, value string) error
+	Set(ctx context.Context, key string, value string) error
+	SetBytes(ctx context.Context, key string, value []byte) error
+	SetWithOptions(ctx context.Context, key string, value string, options SetOptions) error
+	SetEX(ctx context.Context, key string, seconds int, value string) error
+	SetEXBytes(ctx context.Context, key string, seconds int, value []byte) error
+	SetNX(ctx context.Context, key string, value string) (int, error)
+	SetRange(ctx context.Context, key string, offset int, value string) error
+	StrLen(ctx context.Context, key string) (int64, error)
+
+	// Hashes
+	HDel(ctx context.Context, key string, fields []string) (int64, error)
+	HExists(ctx context.Context, key string, field string) (int, error)
+	HGet(ctx context.Context, key string, field string) (string, error)
+	HGetBytes(ctx context.Context, key string, field string) ([]byte, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HIncrBy(ctx context.Context, key string, field string, increment int64) (int64, error)
+	HIncrByFloat(ctx context.Context, key string, field string, increment float64) (float64, error)
+	HKeys(ctx context.Context, key string) ([]string, error)
+	HLen(ctx context.Context, key string) (int64, error)
+	HMGet(ctx context.Context, key string, fields []string) ([]NullString, error)
+	HRandField(ctx context.Context, key string) (string, error)
+	HRandFieldWithCount(ctx context.Context, key string, count int) ([]string, error)
+	HRandFieldWithValues(ctx context.Context, key string, count int) ([]KV, error)
+	HScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []KV, error)
+	HSet(ctx context.Context, key string, values map[string]string) (int64, error)
+	HSetBytes(ctx context.Context, key string, field string, value []byte) (int64, error)
+	HSetNX(ctx context.Context, key string, field string, value string) (int, error)
+	HStrLen(ctx context.Context, key string, field string) (int64, error)
+	HVals(ctx context.Context, key string) ([]string, error)
+
+	// Lists
+	LIndex(ctx context.Context, key string, index int) (string, error)
+	LInsert(ctx context.Context, key string, position InsertPosition, pivot string, element string) (int64, error)
+	LLen(ctx context.Context, key string) (int64, error)
+	LMove(ctx context.Context, source string, destination string, from ListDirection, to ListDirection) (string, error)
+	LPop(ctx context.Context, key string) (string, error)
+	LPopCount(ctx context.Context, key string, count int) ([]string, error)
+	LPos(ctx context.Context, key string, element string, options LPosOptions) (int64, error)
+	LPosWithCount(ctx context.Context, key string, element string, count int, options LPosOptions) ([]int64, error)
+	LPush(ctx context.Context, key string, elements []string) (int64, error)
+	LPushX(ctx context.Context, key string, elements []string) (int64, error)
+	LRange(ctx context.Context, key string, start int, stop int) ([]string, error)
+	LRem(ctx context.Context, key string, count int, element string) (int64, error)
+	LSet(ctx context.Context, key string, index int, element string) error
+	LTrim(ctx context.Context, key string, start int, stop int) error
+	RPop(ctx context.Context, key string) (string, error)
+	RPopCount(ctx context.Context, key string, count int) ([]string, error)
+	RPopLPush(ctx context.Context, source string, destination string) (string, error)
+	RPush(ctx context.Context, key string, elements []string) (int64, error)
+	RPushX(ctx context.Context, key string, elements []string) (int64, error)
+
+	// Sets
+	SAdd(ctx context.Context, key string, members []string) (int64, error)
+	SCard(ctx context.Context, key string) (int64, error)
+	SDiff(ctx context.Context, keys []string) ([]string, error)
+	SDiffStore(ctx context.Context, destination string, keys []string) (int64, error)
+	SInter(ctx context.Context, keys []string) ([]string, error)
+	SInterStore(ctx context.Context, destination string, keys []string) (int64, error)
+	SIsMember(ctx context.Context, key string, member string) (int, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SMIsMember(ctx context.Context, key string, members []string) ([]int, error)
+	SMove(ctx context.Context, source string, destination string, member string) (int, error)
+	SPop(ctx context.Context, key string) (string, error)
+	SPopCount(ctx context.Context, key string, count int) ([]string, error)
+	SRandMember(ctx context.Context, key string) (string, error)
+	SRandMemberWithCount(ctx context.Context, key string, count int) ([]string, error)
+	SRem(ctx context.Context, key string, members []string) (int64, error)
+	SScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []string, error)
+	SUnion(ctx context.Context, keys []string) ([]string, error)
+	SUnionStore(ctx context.Context, destination string, keys []string) (int64, error)
+
+	// Sorted sets
+	ZAdd(ctx context.Context, key string, members []Z) (int64, error)
+	ZAddWithOptions(ctx context.Context, key string, members []Z, options ZAddOptions) (int64, error)
+	ZAddIncr(ctx context.Context, key string, member Z, options ZAddOptions) (float64, error)
+	ZCard(ctx context.Context, key string) (int64, error)
+	ZCount(ctx context.Context, key string, min string, max string) (int64, error)
+	ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error)
+	ZInterStore(ctx context.Context, destination string, keys []string, options ZStoreOptions) (int64, error)
+	ZPopMax(ctx context.Context, key string, count int) ([]Z, error)
+	ZPopMin(ctx context.Context, key string, count int) ([]Z, error)
+	ZRange(ctx context.Context, key string, start string, stop string, options ZRangeOptions) ([]string, error)
+	ZRangeWithScores(ctx context.Context, key string, start string, stop string, options ZRangeOptions) ([]Z, error)
+	ZRank(ctx context.Context, key string, member string) (int64, error)
+	ZRem(ctx context.Context, key string, members []string) (int64, error)
+	ZRemRangeByRank(ctx context.Context, key string, start int, stop int) (int64, error)
+	ZRemRangeByScore(ctx context.Context, key string, min string, max string) (int64, error)
+	ZRevRank(ctx context.Context, key string, member string) (int64, error)
+	ZScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []Z, error)
+	ZScore(ctx context.Context, key string, member string) (float64, error)
+	ZUnionStore(ctx context.Context, destination string, keys []string, options ZStoreOptions) (int64, error)
+
+	// Scripting
+	Eval(ctx context.Context, script string, keys []string, args []string) (interface{}, error)
+	EvalSha(ctx context.Context, sha1 string, keys []string, args []string) (interface{}, error)
+	ScriptExists(ctx context.Context, sha1s []string) ([]int, error)
+	ScriptFlush(ctx context.Context) error
+	ScriptLoad(ctx context.Context, script string) (string, error)
+
+	// Server
+	FlushAll(ctx context.Context) error
+
+	// Generic commands
+	Do(ctx context.Context, args ...interface{}) *Cmd
+}
+
+var (
+	_ Cmdable = (*Upstash)(nil)
+	_ Cmdable = (*Pipeline)(nil)
+	_ Cmdable = (*Tx)(nil)
+)
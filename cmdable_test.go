@@ -0,0 +1,40 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func addUser(ctx context.Context, c upstash.Cmdable, key string, user string) error {
+	_, err := c.SAdd(ctx, key, []string{user})
+	return err
+}
+
+func TestCmdable(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := addUser(ctx, &u, key, "a")
+	require.NoError(t, err)
+
+	p := u.Pipeline()
+	err = addUser(ctx, p, key, "b")
+	require.NoError(t, err)
+	_, err = p.Exec(ctx)
+	require.NoError(t, err)
+
+	tx := u.TxPipeline()
+	err = addUser(ctx, tx, key, "c")
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx)
+	require.NoError(t, err)
+
+	members, err := u.SMembers(ctx, key)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b", "c"}, members)
+}
@@ -0,0 +1,100 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Do sends any command supported by the REST API, e.g. for commands that do
+// not have a method yet. The first argument is the command name, arguments
+// may be strings, byte slices, integers, floats or booleans.
+//
+// Byte slices must be valid UTF-8, except for the last argument, which is
+// sent as raw request body like in SetBytes. Binary values can not be sent
+// in a pipeline.
+//
+//	cmd := u.Do(ctx, "object", "encoding", "key")
+//	encoding, err := cmd.Text()
+//
+// Returns the command, use its accessors to read the result. When called on
+// a Pipeline or Tx the command is queued and the Cmd holds the result once
+// the pipeline was executed.
+func (u *Upstash) Do(ctx context.Context, args ...interface{}) *Cmd {
+	cmd := &Cmd{}
+	if len(args) == 0 {
+		cmd.err = fmt.Errorf("Do requires at least the command name")
+		return cmd
+	}
+	body := make([]string, len(args))
+	for i, arg := range args {
+		if raw, ok := arg.([]byte); ok && i < len(args)-1 && !utf8.Valid(raw) {
+			cmd.err = fmt.Errorf("Argument %d is not valid UTF-8, only the last argument may be binary", i)
+			return cmd
+		}
+		s, err := formatArg(arg)
+		if err != nil {
+			cmd.err = err
+			return cmd
+		}
+		body[i] = s
+	}
+	cmd.args = body
+
+	req := client.Request{Body: body}
+	if raw, ok := args[len(args)-1].([]byte); ok && !utf8.Valid(raw) {
+		req = client.Request{Path: body[:len(body)-1], Body: raw}
+	}
+	res, err := u.client.Write(ctx, req)
+	if q, ok := u.client.(*queue); ok && err == nil {
+		// Return the queued command, its result is set by Exec
+		return q.cmds[len(q.cmds)-1]
+	}
+	cmd.val, cmd.err = res, err
+	return cmd
+}
+
+// Format a single argument of Do as string.
+func formatArg(arg interface{}) (string, error) {
+	switch arg := arg.(type) {
+	case string:
+		return arg, nil
+	case []byte:
+		return string(arg), nil
+	case int:
+		return strconv.Itoa(arg), nil
+	case int8:
+		return strconv.FormatInt(int64(arg), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(arg), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(arg), 10), nil
+	case int64:
+		return strconv.FormatInt(arg, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(arg), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(arg), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(arg), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(arg), 10), nil
+	case uint64:
+		return strconv.FormatUint(arg, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(arg), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(arg, 'f', -1, 64), nil
+	case bool:
+		if arg {
+			return "1", nil
+		}
+		return "0", nil
+	case fmt.Stringer:
+		return arg.String(), nil
+	}
+	return "", fmt.Errorf("Unsupported argument type %T: %v", arg, arg)
+}
@@ -0,0 +1,106 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	status, err := u.Do(ctx, "set", key, 41).Text()
+	require.NoError(t, err)
+	require.Equal(t, "OK", status)
+
+	n, err := u.Do(ctx, "incr", key).Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+
+	f, err := u.Do(ctx, "incrbyfloat", key, 0.5).Float64()
+	require.NoError(t, err)
+	require.Equal(t, 42.5, f)
+
+	exists, err := u.Do(ctx, "exists", key).Bool()
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = u.Do(ctx, "get", uuid.NewString()).Text()
+	require.ErrorIs(t, err, upstash.ErrNil)
+
+	err = u.Do(ctx, "incr", key).Err()
+	require.Error(t, err)
+}
+
+func TestDoCollections(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Do(ctx, "hset", key, "a", "1", "b", []byte("2")).Err()
+	require.NoError(t, err)
+
+	m, err := u.Do(ctx, "hgetall", key).StringMap()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1", "b": "2"}, m)
+
+	keys, err := u.Do(ctx, "hkeys", key).StringSlice()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, keys)
+
+	values, err := u.Do(ctx, "hmget", key, "a", "c").Slice()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"1", nil}, values)
+}
+
+func TestDoArgs(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	cmd := u.Do(ctx)
+	require.Error(t, cmd.Err())
+
+	cmd = u.Do(ctx, "set", "key", struct{}{})
+	require.Error(t, cmd.Err())
+	require.Nil(t, cmd.Args())
+}
+
+func TestDoPipeline(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	p := u.Pipeline()
+	set := p.Do(ctx, "set", key, "1")
+	incr := p.Do(ctx, "incrby", key, int64(2))
+	require.Equal(t, 2, p.Len())
+
+	_, err := p.Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, set.Err())
+	n, err := incr.Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+}
+
+func TestDoBytes(t *testing.T) {
+	key := uuid.NewString()
+	value := []byte{0x00, 0xff, 0xfe}
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Do(ctx, "set", key, value).Err()
+	require.NoError(t, err)
+
+	got, err := u.GetBytes(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	err = u.Do(ctx, "hset", key, value, "value").Err()
+	require.Error(t, err)
+}
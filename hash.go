@@ -0,0 +1,268 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Removes the specified fields from the hash stored at key. Specified fields
+// that do not exist within this hash are ignored. If key does not exist, it
+// is treated as an empty hash and this command returns 0.
+//
+// Returns the number of fields that were removed from the hash, not
+// including specified but non existing fields.
+//
+// https://redis.io/commands/hdel
+func (u *Upstash) HDel(ctx context.Context, key string, fields []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"hdel", key}, fields...),
+	}))
+}
+
+// Returns if field is an existing field in the hash stored at key.
+//
+// Return:
+// - 1 if the hash contains field
+// - 0 if the hash does not contain field, or key does not exist
+//
+// https://redis.io/commands/hexists
+func (u *Upstash) HExists(ctx context.Context, key string, field string) (int, error) {
+	return intResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hexists", key, field},
+	}))
+}
+
+// Returns the value associated with field in the hash stored at key.
+//
+// Returns the value of field, or ErrNil when field is not present in
+// the hash or key does not exist.
+//
+// https://redis.io/commands/hget
+func (u *Upstash) HGet(ctx context.Context, key string, field string) (string, error) {
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hget", key, field},
+	})))
+}
+
+// Same as HGet but returns the value as bytes, for binary values stored
+// with HSetBytes.
+//
+// https://redis.io/commands/hget
+func (u *Upstash) HGetBytes(ctx context.Context, key string, field string) ([]byte, error) {
+	return bytesResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hget", key, field},
+	})))
+}
+
+// Returns all fields and values of the hash stored at key.
+//
+// Returns a map of fields to their values, or an empty map when key does
+// not exist.
+//
+// https://redis.io/commands/hgetall
+func (u *Upstash) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return stringMapResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hgetall", key},
+	}))
+}
+
+// Increments the number stored at field in the hash stored at key by
+// increment. If key does not exist, a new key holding a hash is created. If
+// field does not exist the value is set to 0 before the operation is
+// performed.
+//
+// Returns the value at field after the increment operation.
+//
+// https://redis.io/commands/hincrby
+func (u *Upstash) HIncrBy(ctx context.Context, key string, field string, increment int64) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: []string{"hincrby", key, field, fmt.Sprintf("%d", increment)},
+	}))
+}
+
+// Increment the specified field of a hash stored at key, and representing a
+// floating point number, by the specified increment. If the increment value
+// is negative, the result is to have the hash field value decremented
+// instead of incremented. If the field does not exist, it is set to 0
+// before performing the operation.
+//
+// Returns the value of field after the increment.
+//
+// https://redis.io/commands/hincrbyfloat
+func (u *Upstash) HIncrByFloat(ctx context.Context, key string, field string, increment float64) (float64, error) {
+	return floatResult(u.client.Write(ctx, client.Request{
+		Body: []string{"hincrbyfloat", key, field, strconv.FormatFloat(increment, 'f', -1, 64)},
+	}))
+}
+
+// Returns all field names in the hash stored at key.
+//
+// Returns the list of fields in the hash, or an empty list when key does
+// not exist.
+//
+// https://redis.io/commands/hkeys
+func (u *Upstash) HKeys(ctx context.Context, key string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hkeys", key},
+	}))
+}
+
+// Returns the number of fields contained in the hash stored at key.
+//
+// Returns the number of fields in the hash, or 0 when key does not exist.
+//
+// https://redis.io/commands/hlen
+func (u *Upstash) HLen(ctx context.Context, key string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"hlen", key},
+	}))
+}
+
+// Returns the values associated with the specified fields in the hash
+// stored at key.
+//
+// For every field that does not exist in the hash, an invalid NullString
+// is returned. Because non-existing keys are treated as empty hashes,
+// running HMGET against a non-existing key will return a list of invalid
+// values.
+//
+// Returns a list of values associated with the given fields, in the same
+// order as they are requested.
+//
+// https://redis.io/commands/hmget
+func (u *Upstash) HMGet(ctx context.Context, key string, fields []string) ([]NullString, error) {
+	return nullStringSliceResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"hmget", key}, fields...),
+	}))
+}
+
+// Returns a random field from the hash value stored at key.
+//
+// Returns the randomly selected field, or ErrNil when key does not exist.
+//
+// https://redis.io/commands/hrandfield
+func (u *Upstash) HRandField(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hrandfield", key},
+	})))
+}
+
+// Same as HRandField but returns up to count distinct fields. If count is
+// negative, the same field may be returned multiple times and exactly the
+// absolute value of count fields are returned.
+//
+// https://redis.io/commands/hrandfield
+func (u *Upstash) HRandFieldWithCount(ctx context.Context, key string, count int) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hrandfield", key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+// Same as HRandFieldWithCount but returns the fields together with their
+// values.
+//
+// https://redis.io/commands/hrandfield
+func (u *Upstash) HRandFieldWithValues(ctx context.Context, key string, count int) ([]KV, error) {
+	return kvSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hrandfield", key, fmt.Sprintf("%d", count), "withvalues"},
+	}))
+}
+
+// Iterates fields of the hash stored at key, starting at cursor. Start
+// with a cursor of 0 and call HSCAN with the returned cursor until it
+// returns 0 again.
+//
+// Returns the cursor for the next call and a page of fields with their
+// values.
+//
+// https://redis.io/commands/hscan
+func (u *Upstash) HScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []KV, error) {
+	next, values, err := scanResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"hscan", key, fmt.Sprintf("%d", cursor)}, scanArgs(options)...),
+	}))
+	if err != nil {
+		return 0, nil, err
+	}
+	pairs, err := toKVs(values)
+	return next, pairs, err
+}
+
+// Sets the specified fields to their respective values in the hash stored
+// at key. This command overwrites the values of specified fields that exist
+// in the hash. If key doesn't exist, a new key holding a hash is created.
+//
+// Returns the number of fields that were added.
+//
+// https://redis.io/commands/hset
+func (u *Upstash) HSet(ctx context.Context, key string, values map[string]string) (int64, error) {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	body := []string{"hset", key}
+	for _, field := range fields {
+		body = append(body, field, values[field])
+	}
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: body,
+	}))
+}
+
+// Same as HSet but sets a single field to a binary value. The value is sent
+// as raw request body instead of JSON, so it may contain any bytes.
+//
+// Returns 1 if field is a new field in the hash, or 0 if it was updated.
+//
+// https://redis.io/commands/hset
+func (u *Upstash) HSetBytes(ctx context.Context, key string, field string, value []byte) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Path: []string{"hset", key, field},
+		Body: value,
+	}))
+}
+
+// Sets field in the hash stored at key to value, only if field does not yet
+// exist. If key does not exist, a new key holding a hash is created. If
+// field already exists, this operation has no effect.
+//
+// Return:
+// - 1 if field is a new field in the hash and value was set
+// - 0 if field already exists in the hash and no operation was performed
+//
+// https://redis.io/commands/hsetnx
+func (u *Upstash) HSetNX(ctx context.Context, key string, field string, value string) (int, error) {
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: []string{"hsetnx", key, field, value},
+	}))
+}
+
+// Returns the string length of the value associated with field in the hash
+// stored at key.
+//
+// Returns the string length of the value associated with field, or 0 when
+// field is not present in the hash or key does not exist at all.
+//
+// https://redis.io/commands/hstrlen
+func (u *Upstash) HStrLen(ctx context.Context, key string, field string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"hstrlen", key, field},
+	}))
+}
+
+// Returns all values in the hash stored at key.
+//
+// Returns the list of values in the hash, or an empty list when key does
+// not exist.
+//
+// https://redis.io/commands/hvals
+func (u *Upstash) HVals(ctx context.Context, key string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"hvals", key},
+	}))
+}
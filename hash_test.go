@@ -0,0 +1,248 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHSet(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	added, err := u.HSet(ctx, key, map[string]string{"f1": "v1", "f2": "v2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), added)
+
+	added, err = u.HSet(ctx, key, map[string]string{"f2": "v3", "f3": "v4"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), added)
+
+	got, err := u.HGetAll(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"f1": "v1", "f2": "v3", "f3": "v4"}, got)
+}
+
+func TestHSetNX(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	set, err := u.HSetNX(ctx, key, "field", "v1")
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	set, err = u.HSetNX(ctx, key, "field", "v2")
+	require.NoError(t, err)
+	require.Equal(t, 0, set)
+
+	got, err := u.HGet(ctx, key, "field")
+	require.NoError(t, err)
+	require.Equal(t, "v1", got)
+}
+
+func TestHGet(t *testing.T) {
+	key := uuid.NewString()
+	value := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"a field/with?special chars": value})
+	require.NoError(t, err)
+
+	got, err := u.HGet(ctx, key, "a field/with?special chars")
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	missing, err := u.HGet(ctx, key, "missing")
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", missing)
+}
+
+func TestHGetAllWithNonExistentKey(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	got, err := u.HGetAll(ctx, uuid.NewString())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{}, got)
+}
+
+func TestHMGet(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"f1": "v1", "empty": ""})
+	require.NoError(t, err)
+
+	got, err := u.HMGet(ctx, key, []string{"f1", "missing", "empty"})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.NullString{
+		{Value: "v1", Valid: true},
+		{},
+		{Value: "", Valid: true},
+	}, got)
+}
+
+func TestHDel(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"f1": "v1", "f2": "v2", "f3": "v3"})
+	require.NoError(t, err)
+
+	removed, err := u.HDel(ctx, key, []string{"f1", "f2", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), removed)
+
+	length, err := u.HLen(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), length)
+}
+
+func TestHExists(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"field": "value"})
+	require.NoError(t, err)
+
+	exists, err := u.HExists(ctx, key, "field")
+	require.NoError(t, err)
+	require.Equal(t, 1, exists)
+
+	exists, err = u.HExists(ctx, key, "missing")
+	require.NoError(t, err)
+	require.Equal(t, 0, exists)
+}
+
+func TestHIncrBy(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	after, err := u.HIncrBy(ctx, key, "counter", 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), after)
+
+	after, err = u.HIncrBy(ctx, key, "counter", -2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), after)
+}
+
+func TestHIncrByFloat(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"field": "10.5"})
+	require.NoError(t, err)
+
+	after, err := u.HIncrByFloat(ctx, key, "field", 0.1)
+	require.NoError(t, err)
+	require.Equal(t, 10.6, after)
+}
+
+func TestHKeysAndHVals(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"f1": "v1", "f2": "v2"})
+	require.NoError(t, err)
+
+	fields, err := u.HKeys(ctx, key)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"f1", "f2"}, fields)
+
+	values, err := u.HVals(ctx, key)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"v1", "v2"}, values)
+}
+
+func TestHStrLen(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"field": "hello"})
+	require.NoError(t, err)
+
+	length, err := u.HStrLen(ctx, key, "field")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), length)
+}
+
+func TestHRandField(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.HSet(ctx, key, map[string]string{"f1": "v1", "f2": "v2"})
+	require.NoError(t, err)
+
+	field, err := u.HRandField(ctx, key)
+	require.NoError(t, err)
+	require.Contains(t, []string{"f1", "f2"}, field)
+
+	fields, err := u.HRandFieldWithCount(ctx, key, 5)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"f1", "f2"}, fields)
+
+	pairs, err := u.HRandFieldWithValues(ctx, key, -3)
+	require.NoError(t, err)
+	require.Len(t, pairs, 3)
+	for _, kv := range pairs {
+		require.Contains(t, []upstash.KV{{Key: "f1", Value: "v1"}, {Key: "f2", Value: "v2"}}, kv)
+	}
+}
+
+func TestHScan(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	values := map[string]string{}
+	for i := 0; i < 20; i++ {
+		values[uuid.NewString()] = uuid.NewString()
+	}
+	_, err := u.HSet(ctx, key, values)
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	cursor := uint64(0)
+	for {
+		next, pairs, err := u.HScan(ctx, key, cursor, upstash.ScanOptions{Count: 5})
+		require.NoError(t, err)
+		for _, kv := range pairs {
+			got[kv.Key] = kv.Value
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	require.Equal(t, values, got)
+}
+
+func TestHSetBytes(t *testing.T) {
+	key := uuid.NewString()
+	value := []byte{0xff, 0x00, 0xfe}
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	added, err := u.HSetBytes(ctx, key, "field", value)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), added)
+
+	got, err := u.HGetBytes(ctx, key, "field")
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+}
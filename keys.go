@@ -0,0 +1,250 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+const (
+	// Returned by TTL and PTTL when the key exists but has no associated
+	// expire.
+	NoExpiration time.Duration = -1
+
+	// Returned by TTL and PTTL when the key does not exist.
+	KeyNotFound time.Duration = -2
+)
+
+// Copies the value stored at the source key to the destination key. By
+// default the command fails when the destination key already exists, set
+// replace to remove the destination key before copying the value to it.
+//
+// Return:
+// - 1 if source was copied
+// - 0 if source was not copied
+//
+// https://redis.io/commands/copy
+func (u *Upstash) Copy(ctx context.Context, source string, destination string, replace bool) (int, error) {
+	body := []string{"copy", source, destination}
+	if replace {
+		body = append(body, "replace")
+	}
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: body,
+	}))
+}
+
+// Removes the specified keys. A key is ignored if it does not exist.
+//
+// Returns the number of keys that were removed.
+//
+// https://redis.io/commands/del
+func (u *Upstash) Del(ctx context.Context, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"del"}, keys...),
+	}))
+}
+
+// Returns if keys exist. The same key mentioned multiple times is counted
+// multiple times.
+//
+// Returns the number of keys that exist from those specified as arguments.
+//
+// https://redis.io/commands/exists
+func (u *Upstash) Exists(ctx context.Context, keys []string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: append([]string{"exists"}, keys...),
+	}))
+}
+
+func (u *Upstash) expire(ctx context.Context, command string, key string, value int64, options ExpireOptions) (int, error) {
+	body := []string{command, key, fmt.Sprintf("%d", value)}
+	if options.NX && options.XX {
+		return 0, fmt.Errorf("Only one of NX and XX may be set")
+	}
+	// XX may be combined with GT or LT
+	exclusive := []string{}
+	if options.NX {
+		body = append(body, "nx")
+		exclusive = append(exclusive, "NX")
+	}
+	if options.XX {
+		body = append(body, "xx")
+	}
+	if options.GT {
+		body = append(body, "gt")
+		exclusive = append(exclusive, "GT")
+	}
+	if options.LT {
+		body = append(body, "lt")
+		exclusive = append(exclusive, "LT")
+	}
+	if len(exclusive) > 1 {
+		return 0, fmt.Errorf("Only one of NX, GT and LT may be set, got %s", strings.Join(exclusive, ", "))
+	}
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: body,
+	}))
+}
+
+// Set a timeout on key, in seconds. After the timeout has expired, the key
+// will automatically be deleted. The timeout is cleared by commands that
+// overwrite the contents of the key, like SET or GETSET.
+//
+// Return:
+// - 1 if the timeout was set
+// - 0 if the timeout was not set, e.g. key doesn't exist or the options
+// prevented the operation
+//
+// https://redis.io/commands/expire
+func (u *Upstash) Expire(ctx context.Context, key string, seconds int, options ExpireOptions) (int, error) {
+	return u.expire(ctx, "expire", key, int64(seconds), options)
+}
+
+// Same as Expire but key will expire at the given point in time, with a
+// precision of one second. A timestamp in the past will delete the key
+// immediately.
+//
+// https://redis.io/commands/expireat
+func (u *Upstash) ExpireAt(ctx context.Context, key string, timestamp time.Time, options ExpireOptions) (int, error) {
+	return u.expire(ctx, "expireat", key, timestamp.Unix(), options)
+}
+
+// Remove the existing timeout on key, turning the key from volatile to
+// persistent.
+//
+// Return:
+// - 1 if the timeout was removed
+// - 0 if key does not exist or does not have an associated timeout
+//
+// https://redis.io/commands/persist
+func (u *Upstash) Persist(ctx context.Context, key string) (int, error) {
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: []string{"persist", key},
+	}))
+}
+
+// Same as Expire but the timeout is specified in milliseconds.
+//
+// https://redis.io/commands/pexpire
+func (u *Upstash) PExpire(ctx context.Context, key string, milliseconds int, options ExpireOptions) (int, error) {
+	return u.expire(ctx, "pexpire", key, int64(milliseconds), options)
+}
+
+// Same as ExpireAt but with a precision of one millisecond.
+//
+// https://redis.io/commands/pexpireat
+func (u *Upstash) PExpireAt(ctx context.Context, key string, timestamp time.Time, options ExpireOptions) (int, error) {
+	return u.expire(ctx, "pexpireat", key, timestamp.UnixNano()/int64(time.Millisecond), options)
+}
+
+func (u *Upstash) ttl(ctx context.Context, command string, key string, unit time.Duration) (time.Duration, error) {
+	n, err := int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{command, key},
+	}))
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case -1:
+		return NoExpiration, nil
+	case -2:
+		return KeyNotFound, nil
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Same as TTL but with a precision of one millisecond.
+//
+// https://redis.io/commands/pttl
+func (u *Upstash) PTTL(ctx context.Context, key string) (time.Duration, error) {
+	return u.ttl(ctx, "pttl", key, time.Millisecond)
+}
+
+// Return a random key from the currently selected database.
+//
+// Returns the random key, or ErrNil when the database is empty.
+//
+// https://redis.io/commands/randomkey
+func (u *Upstash) RandomKey(ctx context.Context) (string, error) {
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"randomkey"},
+	})))
+}
+
+// Renames key to newKey. An error is returned when key does not exist. If
+// newKey already exists it is overwritten.
+//
+// https://redis.io/commands/rename
+func (u *Upstash) Rename(ctx context.Context, key string, newKey string) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Body: []string{"rename", key, newKey},
+	})
+	return err
+}
+
+// Renames key to newKey if newKey does not yet exist. An error is returned
+// when key does not exist.
+//
+// Return:
+// - 1 if key was renamed to newKey
+// - 0 if newKey already exists
+//
+// https://redis.io/commands/renamenx
+func (u *Upstash) RenameNX(ctx context.Context, key string, newKey string) (int, error) {
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: []string{"renamenx", key, newKey},
+	}))
+}
+
+// Alters the last access time of keys. A key is ignored if it does not
+// exist.
+//
+// Returns the number of keys that were touched.
+//
+// https://redis.io/commands/touch
+func (u *Upstash) Touch(ctx context.Context, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"touch"}, keys...),
+	}))
+}
+
+// Returns the remaining time to live of a key that has a timeout, with a
+// precision of one second.
+//
+// Returns the time to live, NoExpiration if the key exists but has no
+// associated expire, or KeyNotFound if the key does not exist.
+//
+// https://redis.io/commands/ttl
+func (u *Upstash) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return u.ttl(ctx, "ttl", key, time.Second)
+}
+
+// Returns the string representation of the type of the value stored at key.
+// The different types that can be returned are: string, list, set, zset and
+// hash.
+//
+// Returns the type of key, or "none" when key does not exist.
+//
+// https://redis.io/commands/type
+func (u *Upstash) Type(ctx context.Context, key string) (string, error) {
+	return stringResult(u.client.Read(ctx, client.Request{
+		Path: []string{"type", key},
+	}))
+}
+
+// This command is very similar to DEL: it removes the specified keys. The
+// actual memory reclaiming happens asynchronously in a different thread, so
+// it is not blocking.
+//
+// Returns the number of keys that were unlinked.
+//
+// https://redis.io/commands/unlink
+func (u *Upstash) Unlink(ctx context.Context, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"unlink"}, keys...),
+	}))
+}
@@ -0,0 +1,274 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDel(t *testing.T) {
+	key1 := uuid.NewString()
+	key2 := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.MSet(ctx, []upstash.KV{{Key: key1, Value: "1"}, {Key: key2, Value: "2"}})
+	require.NoError(t, err)
+
+	exists, err := u.Exists(ctx, []string{key1, key2, key1})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), exists)
+
+	removed, err := u.Del(ctx, []string{key1, key2, uuid.NewString()})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), removed)
+
+	exists, err = u.Exists(ctx, []string{key1, key2})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), exists)
+}
+
+func TestUnlink(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	removed, err := u.Unlink(ctx, []string{key})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+}
+
+func TestExpire(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	set, err := u.Expire(ctx, key, 1, upstash.ExpireOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	time.Sleep(2 * time.Second)
+
+	got, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", got)
+}
+
+func TestExpireWithOptions(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	set, err := u.Expire(ctx, key, 100, upstash.ExpireOptions{XX: true})
+	require.NoError(t, err)
+	require.Equal(t, 0, set)
+
+	set, err = u.Expire(ctx, key, 100, upstash.ExpireOptions{NX: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	set, err = u.PExpire(ctx, key, 50000, upstash.ExpireOptions{GT: true})
+	require.NoError(t, err)
+	require.Equal(t, 0, set)
+
+	set, err = u.PExpire(ctx, key, 50000, upstash.ExpireOptions{LT: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	ttl, err := u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.InDelta(t, 50*time.Second, ttl, float64(time.Second))
+
+	set, err = u.PExpire(ctx, key, 60000, upstash.ExpireOptions{XX: true, GT: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	_, err = u.Expire(ctx, key, 100, upstash.ExpireOptions{NX: true, GT: true})
+	require.Error(t, err)
+	_, err = u.Expire(ctx, key, 100, upstash.ExpireOptions{GT: true, LT: true})
+	require.Error(t, err)
+	_, err = u.Expire(ctx, key, 100, upstash.ExpireOptions{NX: true, XX: true})
+	require.Error(t, err)
+}
+
+func TestExpireAt(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	set, err := u.ExpireAt(ctx, key, time.Now().Add(time.Hour), upstash.ExpireOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	ttl, err := u.PTTL(ctx, key)
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, ttl, float64(2*time.Second))
+
+	set, err = u.PExpireAt(ctx, key, time.Now().Add(-time.Second), upstash.ExpireOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, set)
+
+	exists, err := u.Exists(ctx, []string{key})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), exists)
+}
+
+func TestTTL(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	ttl, err := u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, upstash.KeyNotFound, ttl)
+
+	err = u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	ttl, err = u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, upstash.NoExpiration, ttl)
+
+	err = u.SetEX(ctx, key, 10, "value")
+	require.NoError(t, err)
+
+	ttl, err = u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, ttl)
+}
+
+func TestPersist(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.SetEX(ctx, key, 10, "value")
+	require.NoError(t, err)
+
+	removed, err := u.Persist(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	ttl, err := u.PTTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, upstash.NoExpiration, ttl)
+}
+
+func TestType(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	typ, err := u.Type(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "none", typ)
+
+	_, err = u.SAdd(ctx, key, []string{"a"})
+	require.NoError(t, err)
+
+	typ, err = u.Type(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "set", typ)
+}
+
+func TestRename(t *testing.T) {
+	key := uuid.NewString()
+	newKey := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Rename(ctx, key, newKey)
+	require.Error(t, err)
+
+	err = u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	err = u.Rename(ctx, key, newKey)
+	require.NoError(t, err)
+
+	got, err := u.Get(ctx, newKey)
+	require.NoError(t, err)
+	require.Equal(t, "value", got)
+}
+
+func TestRenameNX(t *testing.T) {
+	key := uuid.NewString()
+	newKey := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.MSet(ctx, []upstash.KV{{Key: key, Value: "1"}, {Key: newKey, Value: "2"}})
+	require.NoError(t, err)
+
+	renamed, err := u.RenameNX(ctx, key, newKey)
+	require.NoError(t, err)
+	require.Equal(t, 0, renamed)
+
+	renamed, err = u.RenameNX(ctx, key, uuid.NewString())
+	require.NoError(t, err)
+	require.Equal(t, 1, renamed)
+}
+
+func TestCopy(t *testing.T) {
+	source := uuid.NewString()
+	destination := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.MSet(ctx, []upstash.KV{{Key: source, Value: "1"}, {Key: destination, Value: "2"}})
+	require.NoError(t, err)
+
+	copied, err := u.Copy(ctx, source, destination, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, copied)
+
+	copied, err = u.Copy(ctx, source, destination, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, copied)
+
+	got, err := u.Get(ctx, destination)
+	require.NoError(t, err)
+	require.Equal(t, "1", got)
+}
+
+func TestTouch(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	touched, err := u.Touch(ctx, []string{key, uuid.NewString()})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), touched)
+}
+
+func TestRandomKey(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	got, err := u.RandomKey(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, "", got)
+}
@@ -0,0 +1,257 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Returns the element at index in the list stored at key. The index is
+// zero-based, so 0 means the first element, 1 the second element and so on.
+// Negative indices can be used to designate elements starting at the tail
+// of the list. Here, -1 means the last element, -2 means the penultimate
+// and so forth.
+//
+// Returns the requested element, or ErrNil when index is out of range.
+//
+// https://redis.io/commands/lindex
+func (u *Upstash) LIndex(ctx context.Context, key string, index int) (string, error) {
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"lindex", key, fmt.Sprintf("%d", index)},
+	})))
+}
+
+// Inserts element in the list stored at key either before or after the
+// reference value pivot. When key does not exist, it is considered an empty
+// list and no operation is performed.
+//
+// Returns the length of the list after the insert operation, or -1 when the
+// value pivot was not found.
+//
+// https://redis.io/commands/linsert
+func (u *Upstash) LInsert(ctx context.Context, key string, position InsertPosition, pivot string, element string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: []string{"linsert", key, string(position), pivot, element},
+	}))
+}
+
+// Returns the length of the list stored at key. If key does not exist, it is
+// interpreted as an empty list and 0 is returned.
+//
+// Returns the length of the list at key.
+//
+// https://redis.io/commands/llen
+func (u *Upstash) LLen(ctx context.Context, key string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"llen", key},
+	}))
+}
+
+// Atomically returns and removes the first/last element (head/tail
+// depending on the from argument) of the list stored at source, and pushes
+// the element at the first/last element (head/tail depending on the to
+// argument) of the list stored at destination.
+//
+// Returns the element being popped and pushed, or ErrNil when source is
+// empty.
+//
+// https://redis.io/commands/lmove
+func (u *Upstash) LMove(ctx context.Context, source string, destination string, from ListDirection, to ListDirection) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"lmove", source, destination, string(from), string(to)},
+	})))
+}
+
+// Removes and returns the first element of the list stored at key.
+//
+// Returns the value of the first element, or ErrNil when key does not
+// exist.
+//
+// https://redis.io/commands/lpop
+func (u *Upstash) LPop(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"lpop", key},
+	})))
+}
+
+// Same as LPop but removes and returns up to count elements.
+//
+// Returns the popped elements, or an empty list when key does not exist.
+//
+// https://redis.io/commands/lpop
+func (u *Upstash) LPopCount(ctx context.Context, key string, count int) ([]string, error) {
+	return stringSliceResult(u.client.Write(ctx, client.Request{
+		Body: []string{"lpop", key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+func lposArgs(key string, element string, options LPosOptions) []string {
+	args := []string{"lpos", key, element}
+	if options.Rank != 0 {
+		args = append(args, "rank", fmt.Sprintf("%d", options.Rank))
+	}
+	if options.MaxLen != 0 {
+		args = append(args, "maxlen", fmt.Sprintf("%d", options.MaxLen))
+	}
+	return args
+}
+
+// Returns the index of the first element matching element in the list
+// stored at key. The list is scanned from head to tail unless a negative
+// rank is given in the options.
+//
+// Returns the index of the matching element, or ErrNil when there is no
+// match.
+//
+// https://redis.io/commands/lpos
+func (u *Upstash) LPos(ctx context.Context, key string, element string, options LPosOptions) (int64, error) {
+	return int64Result(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: lposArgs(key, element, options),
+	})))
+}
+
+// Same as LPos but returns the indices of up to count matching elements.
+// A count of 0 returns all matches.
+//
+// https://redis.io/commands/lpos
+func (u *Upstash) LPosWithCount(ctx context.Context, key string, element string, count int, options LPosOptions) ([]int64, error) {
+	return int64SliceResult(u.client.Read(ctx, client.Request{
+		Path: append(lposArgs(key, element, options), "count", fmt.Sprintf("%d", count)),
+	}))
+}
+
+// Insert all the specified values at the head of the list stored at key. If
+// key does not exist, it is created as empty list before performing the push
+// operations. Elements are inserted one after the other, so the last element
+// ends up at the head of the list.
+//
+// Returns the length of the list after the push operation.
+//
+// https://redis.io/commands/lpush
+func (u *Upstash) LPush(ctx context.Context, key string, elements []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"lpush", key}, elements...),
+	}))
+}
+
+// Same as LPush but only pushes when key already exists and holds a list.
+//
+// Returns the length of the list after the push operation.
+//
+// https://redis.io/commands/lpushx
+func (u *Upstash) LPushX(ctx context.Context, key string, elements []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"lpushx", key}, elements...),
+	}))
+}
+
+// Returns the specified elements of the list stored at key. The offsets
+// start and stop are zero-based indexes and both inclusive. They can also be
+// negative numbers indicating offsets from the end of the list.
+//
+// Returns the list of elements in the specified range.
+//
+// https://redis.io/commands/lrange
+func (u *Upstash) LRange(ctx context.Context, key string, start int, stop int) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"lrange", key, fmt.Sprintf("%d", start), fmt.Sprintf("%d", stop)},
+	}))
+}
+
+// Removes the first count occurrences of elements equal to element from the
+// list stored at key. A positive count removes elements moving from head to
+// tail, a negative count from tail to head and 0 removes all occurrences.
+//
+// Returns the number of removed elements.
+//
+// https://redis.io/commands/lrem
+func (u *Upstash) LRem(ctx context.Context, key string, count int, element string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: []string{"lrem", key, fmt.Sprintf("%d", count), element},
+	}))
+}
+
+// Sets the list element at index to element. An error is returned for out
+// of range indexes.
+//
+// https://redis.io/commands/lset
+func (u *Upstash) LSet(ctx context.Context, key string, index int, element string) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Body: []string{"lset", key, fmt.Sprintf("%d", index), element},
+	})
+	return err
+}
+
+// Trim an existing list so that it will contain only the specified range of
+// elements. Both start and stop are zero-based indexes and can be negative
+// to designate offsets from the end of the list.
+//
+// https://redis.io/commands/ltrim
+func (u *Upstash) LTrim(ctx context.Context, key string, start int, stop int) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Body: []string{"ltrim", key, fmt.Sprintf("%d", start), fmt.Sprintf("%d", stop)},
+	})
+	return err
+}
+
+// Removes and returns the last element of the list stored at key.
+//
+// Returns the value of the last element, or ErrNil when key does not
+// exist.
+//
+// https://redis.io/commands/rpop
+func (u *Upstash) RPop(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"rpop", key},
+	})))
+}
+
+// Same as RPop but removes and returns up to count elements.
+//
+// Returns the popped elements, or an empty list when key does not exist.
+//
+// https://redis.io/commands/rpop
+func (u *Upstash) RPopCount(ctx context.Context, key string, count int) ([]string, error) {
+	return stringSliceResult(u.client.Write(ctx, client.Request{
+		Body: []string{"rpop", key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+// Atomically returns and removes the last element of the list stored at
+// source, and pushes the element at the first element of the list stored at
+// destination. Equivalent to LMove with Right and Left.
+//
+// Returns the element being popped and pushed, or ErrNil when source is
+// empty.
+//
+// https://redis.io/commands/rpoplpush
+func (u *Upstash) RPopLPush(ctx context.Context, source string, destination string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"rpoplpush", source, destination},
+	})))
+}
+
+// Insert all the specified values at the tail of the list stored at key. If
+// key does not exist, it is created as empty list before performing the push
+// operation.
+//
+// Returns the length of the list after the push operation.
+//
+// https://redis.io/commands/rpush
+func (u *Upstash) RPush(ctx context.Context, key string, elements []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"rpush", key}, elements...),
+	}))
+}
+
+// Same as RPush but only pushes when key already exists and holds a list.
+//
+// Returns the length of the list after the push operation.
+//
+// https://redis.io/commands/rpushx
+func (u *Upstash) RPushX(ctx context.Context, key string, elements []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"rpushx", key}, elements...),
+	}))
+}
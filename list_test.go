@@ -0,0 +1,225 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLPush(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	length, err := u.LPush(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), length)
+
+	got, err := u.LRange(ctx, key, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b", "a"}, got)
+}
+
+func TestRPush(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	length, err := u.RPush(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), length)
+
+	got, err := u.LRange(ctx, key, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, got)
+}
+
+func TestPushXWithNonExistentKey(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	length, err := u.LPushX(ctx, key, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), length)
+
+	length, err = u.RPushX(ctx, key, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), length)
+
+	length, err = u.LLen(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), length)
+}
+
+func TestLPop(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+
+	got, err := u.LPop(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "a", got)
+
+	popped, err := u.LPopCount(ctx, key, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, popped)
+
+	got, err = u.RPop(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "d", got)
+
+	got, err = u.LPop(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", got)
+}
+
+func TestRPopCount(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	popped, err := u.RPopCount(ctx, key, 5)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b", "a"}, popped)
+
+	popped, err = u.RPopCount(ctx, key, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{}, popped)
+}
+
+func TestLIndexAndLSet(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	err = u.LSet(ctx, key, -1, "z")
+	require.NoError(t, err)
+
+	got, err := u.LIndex(ctx, key, 2)
+	require.NoError(t, err)
+	require.Equal(t, "z", got)
+
+	err = u.LSet(ctx, key, 10, "z")
+	require.Error(t, err)
+}
+
+func TestLMove(t *testing.T) {
+	source := uuid.NewString()
+	destination := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, source, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	moved, err := u.LMove(ctx, source, destination, upstash.Right, upstash.Left)
+	require.NoError(t, err)
+	require.Equal(t, "c", moved)
+
+	moved, err = u.RPopLPush(ctx, source, destination)
+	require.NoError(t, err)
+	require.Equal(t, "b", moved)
+
+	got, err := u.LRange(ctx, destination, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, got)
+}
+
+func TestLPos(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "c", "1", "2", "3", "c", "c"})
+	require.NoError(t, err)
+
+	index, err := u.LPos(ctx, key, "c", upstash.LPosOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), index)
+
+	index, err = u.LPos(ctx, key, "c", upstash.LPosOptions{Rank: -1})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), index)
+
+	index, err = u.LPos(ctx, key, "c", upstash.LPosOptions{MaxLen: 2})
+	require.ErrorIs(t, err, upstash.ErrNil)
+
+	indices, err := u.LPosWithCount(ctx, key, "c", 2, upstash.LPosOptions{Rank: 2})
+	require.NoError(t, err)
+	require.Equal(t, []int64{6, 7}, indices)
+
+	indices, err = u.LPosWithCount(ctx, key, "c", 0, upstash.LPosOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 6, 7}, indices)
+}
+
+func TestLTrim(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+
+	err = u.LTrim(ctx, key, 1, -2)
+	require.NoError(t, err)
+
+	got, err := u.LRange(ctx, key, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, got)
+}
+
+func TestLInsert(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "c"})
+	require.NoError(t, err)
+
+	length, err := u.LInsert(ctx, key, upstash.Before, "c", "b")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), length)
+
+	length, err = u.LInsert(ctx, key, upstash.After, "c", "d")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), length)
+
+	length, err = u.LInsert(ctx, key, upstash.After, "missing", "x")
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), length)
+
+	got, err := u.LRange(ctx, key, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d"}, got)
+}
+
+func TestLRem(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.RPush(ctx, key, []string{"a", "b", "a", "c", "a"})
+	require.NoError(t, err)
+
+	removed, err := u.LRem(ctx, key, -2, "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), removed)
+
+	got, err := u.LRange(ctx, key, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, got)
+}
@@ -0,0 +1,8 @@
+package lock
+
+// Script sources for the fake server, see TestMain.
+const (
+	AcquireSource = acquireSource
+	ReleaseSource = releaseSource
+	ExtendSource  = extendSource
+)
@@ -0,0 +1,303 @@
+// Package lock implements a distributed lock on top of Upstash.
+//
+// A lock is acquired with SET NX PX and a random token, so only the holder
+// of the token can release or extend it. Every acquisition increments a
+// fencing token, which protected resources can use to reject writes from a
+// holder whose lock expired in the meantime.
+//
+//	locker := lock.New(&u, lock.Options{TTL: 10 * time.Second})
+//	l, err := locker.Acquire(ctx, "jobs:cleanup")
+//	if err != nil {
+//		return err
+//	}
+//	defer l.Release(ctx)
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chronark/upstash-go"
+)
+
+var (
+	// Returned by TryAcquire when the lock is held by someone else.
+	ErrNotAcquired = errors.New("Lock not acquired")
+
+	// Returned by Release and Extend when the lock expired or was acquired
+	// by someone else.
+	ErrNotHeld = errors.New("Lock not held")
+)
+
+const (
+	DefaultTTL        = 10 * time.Second
+	DefaultRetryDelay = 100 * time.Millisecond
+)
+
+const (
+	// Set the lock and increment the fencing token in one step, so fencing
+	// tokens are handed out in the order the lock was acquired.
+	acquireSource = `if redis.call("set", KEYS[1], ARGV[1], "nx", "px", ARGV[2]) then
+	return redis.call("incr", KEYS[2])
+end
+return 0`
+
+	releaseSource = `if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`
+
+	extendSource = `if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`
+)
+
+var (
+	acquireScript = upstash.NewScript(acquireSource)
+	releaseScript = upstash.NewScript(releaseSource)
+	extendScript  = upstash.NewScript(extendSource)
+)
+
+type Options struct {
+	// How long the lock is held unless it is extended or released.
+	// Defaults to DefaultTTL, durations below a millisecond are rounded up.
+	TTL time.Duration
+
+	// Wait time between two attempts of Acquire. Defaults to
+	// DefaultRetryDelay.
+	RetryDelay time.Duration
+
+	// Extend the lock in the background until it is released, for tasks
+	// that may run longer than TTL. See Lock.Lost.
+	AutoRenew bool
+}
+
+// Locker acquires locks with the same options.
+type Locker struct {
+	c       upstash.Cmdable
+	options Options
+}
+
+// Creates a new Locker that sends commands with c.
+func New(c upstash.Cmdable, options Options) *Locker {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	// PX 0 is rejected by Redis and renewal needs a positive interval
+	if options.TTL < time.Millisecond {
+		options.TTL = time.Millisecond
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = DefaultRetryDelay
+	}
+	return &Locker{c: c, options: options}
+}
+
+// Acquires the lock at key with a single attempt.
+//
+// Returns the lock, or ErrNotAcquired when it is held by someone else.
+func (l *Locker) TryAcquire(ctx context.Context, key string) (*Lock, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	res, err := acquireScript.Run(ctx, l.c,
+		[]string{key, fenceKey(key)},
+		[]string{token, strconv.FormatInt(l.options.TTL.Milliseconds(), 10)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	fence, err := intResult(res)
+	if err != nil {
+		return nil, err
+	}
+	if fence == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	lock := &Lock{
+		locker: l,
+		key:    key,
+		token:  token,
+		fence:  fence,
+		lost:   make(chan struct{}),
+	}
+	if l.options.AutoRenew {
+		lock.startRenewal()
+	}
+	return lock, nil
+}
+
+// Acquires the lock at key, waiting until it is released by its current
+// holder or until ctx is done.
+//
+// Returns the lock, or the error of ctx when it is done first.
+func (l *Locker) Acquire(ctx context.Context, key string) (*Lock, error) {
+	for {
+		lock, err := l.TryAcquire(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+		timer := time.NewTimer(l.options.RetryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Lock is a lock held by this process.
+type Lock struct {
+	locker *Locker
+	key    string
+	token  string
+	fence  int64
+
+	lost     chan struct{}
+	lostOnce sync.Once
+
+	stopRenewal context.CancelFunc
+	renewalDone chan struct{}
+}
+
+// Returns the key of the lock.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Returns the random token identifying this holder.
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Returns the fencing token, which is greater than the fencing token of
+// every earlier holder of the lock. The counter is stored at key + ":fence"
+// and never expires.
+//
+// Send it with every write to the protected resource and reject writes with
+// a lower fencing token than the highest one seen.
+func (l *Lock) Fence() int64 {
+	return l.fence
+}
+
+// Returns a channel that is closed when automatic renewal failed to extend
+// the lock, because it expired or was acquired by someone else. Never
+// closed without Options.AutoRenew.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Resets the time to live of the lock to ttl. ttl must be positive,
+// durations below a millisecond are rounded up.
+//
+// Returns ErrNotHeld when the lock expired or was acquired by someone else.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	// PEXPIRE with 0 or less would delete the lock
+	if ttl <= 0 {
+		return fmt.Errorf("TTL must be positive, got %s", ttl)
+	}
+	if ttl < time.Millisecond {
+		ttl = time.Millisecond
+	}
+	res, err := extendScript.Run(ctx, l.locker.c,
+		[]string{l.key},
+		[]string{l.token, strconv.FormatInt(ttl.Milliseconds(), 10)},
+	)
+	return heldResult(res, err)
+}
+
+// Stops automatic renewal and deletes the lock.
+//
+// Returns ErrNotHeld when the lock expired or was acquired by someone else.
+func (l *Lock) Release(ctx context.Context) error {
+	if l.stopRenewal != nil {
+		l.stopRenewal()
+		<-l.renewalDone
+	}
+	res, err := releaseScript.Run(ctx, l.locker.c,
+		[]string{l.key},
+		[]string{l.token},
+	)
+	return heldResult(res, err)
+}
+
+// Extend the lock every third of its time to live until it is released or
+// lost. Failed requests are retried on the next tick, the lock is lost when
+// it could not be extended within its time to live.
+func (l *Lock) startRenewal() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.stopRenewal = cancel
+	l.renewalDone = make(chan struct{})
+
+	ttl := l.locker.options.TTL
+	go func() {
+		defer close(l.renewalDone)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		extended := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			reqCtx, cancel := context.WithTimeout(ctx, ttl/3)
+			err := l.Extend(reqCtx, ttl)
+			cancel()
+			switch {
+			case err == nil:
+				extended = time.Now()
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, ErrNotHeld) || time.Since(extended) >= ttl:
+				l.lostOnce.Do(func() { close(l.lost) })
+				return
+			}
+		}
+	}()
+}
+
+func fenceKey(key string) string {
+	return key + ":fence"
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Unable to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func intResult(res interface{}) (int64, error) {
+	n, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("Unexpected result, expected integer: %v", res)
+	}
+	return n, nil
+}
+
+// Turn the result of the release and extend scripts into ErrNotHeld.
+func heldResult(res interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := intResult(res)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
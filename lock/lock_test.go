@@ -0,0 +1,204 @@
+package lock_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chronark/upstash-go"
+	"github.com/chronark/upstash-go/client/clienttest"
+	"github.com/chronark/upstash-go/lock"
+	"github.com/chronark/upstash-go/upstashtest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// Run the tests against an in-memory fake unless a database is configured.
+func TestMain(m *testing.M) {
+	if os.Getenv("UPSTASH_REDIS_REST_URL") == "" {
+		srv := upstashtest.NewServer()
+		handleScripts(srv)
+		os.Setenv("UPSTASH_REDIS_REST_URL", srv.URL)
+		os.Setenv("UPSTASH_REDIS_REST_TOKEN", srv.Token)
+		code := m.Run()
+		srv.Close()
+		os.Exit(code)
+	}
+	os.Exit(m.Run())
+}
+
+// Implement the scripts of the lock, the fake server does not run Lua.
+func handleScripts(srv *upstashtest.Server) {
+	srv.HandleScript(lock.AcquireSource, func(call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, error) {
+		res, err := call("set", keys[0], args[0], "nx", "px", args[1])
+		if err != nil || res == nil {
+			return int64(0), err
+		}
+		return call("incr", keys[1])
+	})
+	srv.HandleScript(lock.ReleaseSource, func(call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, error) {
+		value, err := call("get", keys[0])
+		if err != nil || value != args[0] {
+			return int64(0), err
+		}
+		return call("del", keys[0])
+	})
+	srv.HandleScript(lock.ExtendSource, func(call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, error) {
+		value, err := call("get", keys[0])
+		if err != nil || value != args[0] {
+			return int64(0), err
+		}
+		return call("pexpire", keys[0], args[1])
+	})
+}
+
+func TestAcquire(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	locker := lock.New(&u, lock.Options{})
+	ctx := context.Background()
+
+	l, err := locker.TryAcquire(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, key, l.Key())
+	require.Equal(t, int64(1), l.Fence())
+
+	token, err := u.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, l.Token(), token)
+
+	_, err = locker.TryAcquire(ctx, key)
+	require.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	err = l.Release(ctx)
+	require.NoError(t, err)
+
+	l, err = locker.TryAcquire(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), l.Fence())
+
+	err = l.Release(ctx)
+	require.NoError(t, err)
+}
+
+func TestAcquireWait(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	first, err := lock.New(&u, lock.Options{TTL: 200 * time.Millisecond}).Acquire(ctx, key)
+	require.NoError(t, err)
+
+	locker := lock.New(&u, lock.Options{RetryDelay: 50 * time.Millisecond})
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = locker.Acquire(timeoutCtx, key)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	timeoutCtx, cancel = context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	second, err := locker.Acquire(timeoutCtx, key)
+	require.NoError(t, err)
+	require.Greater(t, second.Fence(), first.Fence())
+
+	err = first.Release(ctx)
+	require.ErrorIs(t, err, lock.ErrNotHeld)
+
+	err = second.Release(ctx)
+	require.NoError(t, err)
+}
+
+func TestExtend(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	locker := lock.New(&u, lock.Options{TTL: 200 * time.Millisecond})
+	ctx := context.Background()
+
+	l, err := locker.Acquire(ctx, key)
+	require.NoError(t, err)
+
+	err = l.Extend(ctx, time.Second)
+	require.NoError(t, err)
+
+	time.Sleep(300 * time.Millisecond)
+
+	_, err = locker.TryAcquire(ctx, key)
+	require.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	_, err = u.Del(ctx, []string{key})
+	require.NoError(t, err)
+
+	err = l.Extend(ctx, time.Second)
+	require.ErrorIs(t, err, lock.ErrNotHeld)
+}
+
+func TestExtendShortTTL(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On().Return(1)
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	l, err := lock.New(&u, lock.Options{}).TryAcquire(ctx, "key")
+	require.NoError(t, err)
+
+	err = l.Extend(ctx, 0)
+	require.Error(t, err)
+	err = l.Extend(ctx, -time.Second)
+	require.Error(t, err)
+
+	err = l.Extend(ctx, time.Microsecond)
+	require.NoError(t, err)
+
+	extend := upstash.NewScript(lock.ExtendSource)
+	require.Len(t, m.Commands(), 2)
+	m.AssertCalled(t, "evalsha", extend.Hash(), "1", "key", l.Token(), "1")
+}
+
+func TestAutoRenew(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	locker := lock.New(&u, lock.Options{TTL: 300 * time.Millisecond, AutoRenew: true})
+	ctx := context.Background()
+
+	l, err := locker.Acquire(ctx, key)
+	require.NoError(t, err)
+
+	time.Sleep(600 * time.Millisecond)
+
+	_, err = locker.TryAcquire(ctx, key)
+	require.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	select {
+	case <-l.Lost():
+		t.Fatal("Lock was lost")
+	default:
+	}
+
+	err = l.Release(ctx)
+	require.NoError(t, err)
+
+	l, err = locker.Acquire(ctx, key)
+	require.NoError(t, err)
+
+	_, err = u.Del(ctx, []string{key})
+	require.NoError(t, err)
+
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lock was not lost")
+	}
+}
+
+func TestShortTTL(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	locker := lock.New(&u, lock.Options{TTL: time.Microsecond, AutoRenew: true})
+	ctx := context.Background()
+
+	l, err := locker.TryAcquire(ctx, key)
+	require.NoError(t, err)
+
+	_ = l.Release(ctx)
+}
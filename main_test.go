@@ -0,0 +1,21 @@
+package upstash_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chronark/upstash-go/upstashtest"
+)
+
+// Run the tests against an in-memory fake unless a database is configured.
+func TestMain(m *testing.M) {
+	if os.Getenv("UPSTASH_REDIS_REST_URL") == "" {
+		srv := upstashtest.NewServer()
+		os.Setenv("UPSTASH_REDIS_REST_URL", srv.URL)
+		os.Setenv("UPSTASH_REDIS_REST_TOKEN", srv.Token)
+		code := m.Run()
+		srv.Close()
+		os.Exit(code)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,203 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Cmd is a command sent with Do or as part of a pipeline. It holds the
+// arguments of the command and, once it was executed, its result or error.
+type Cmd struct {
+	args []string
+	val  interface{}
+	err  error
+}
+
+// Returns the command and its arguments, e.g. ["set", "key", "value"].
+func (c *Cmd) Args() []string {
+	return c.args
+}
+
+// Returns the raw result of the command as decoded from JSON, with integers
+// as int64.
+func (c *Cmd) Val() interface{} {
+	return c.val
+}
+
+// Returns the error returned by Upstash for this command, if any. Errors
+// returned by Redis are of type *client.RedisError.
+func (c *Cmd) Err() error {
+	return c.err
+}
+
+// Returns the error of the command, or ErrNil when the result is nil.
+func (c *Cmd) nilErr() error {
+	if c.err == nil && c.val == nil {
+		return ErrNil
+	}
+	return c.err
+}
+
+// Returns the result as string, or ErrNil when the result is nil.
+func (c *Cmd) Text() (string, error) {
+	return stringResult(c.val, c.nilErr())
+}
+
+// Returns the result as integer, or ErrNil when the result is nil.
+func (c *Cmd) Int() (int, error) {
+	return intResult(c.val, c.nilErr())
+}
+
+// Returns the result as 64 bit integer, or ErrNil when the result is nil.
+func (c *Cmd) Int64() (int64, error) {
+	return int64Result(c.val, c.nilErr())
+}
+
+// Returns the result as float, or ErrNil when the result is nil. Upstash
+// returns integers as numbers and floats as strings, both are accepted.
+func (c *Cmd) Float64() (float64, error) {
+	return floatResult(c.val, c.nilErr())
+}
+
+// Returns the result as boolean, or ErrNil when the result is nil. Integer
+// replies are true unless 0, "OK" and strings like "true" or "1" are true.
+func (c *Cmd) Bool() (bool, error) {
+	return boolResult(c.val, c.nilErr())
+}
+
+// Returns the result as a list of raw values. Nested arrays are returned as
+// []interface{} as well.
+func (c *Cmd) Slice() ([]interface{}, error) {
+	return sliceResult(c.val, c.err)
+}
+
+// Returns the result as a list of strings.
+func (c *Cmd) StringSlice() ([]string, error) {
+	return stringSliceResult(c.val, c.err)
+}
+
+// Returns a flat [key, value, key, value, ...] result as a map.
+func (c *Cmd) StringMap() (map[string]string, error) {
+	return stringMapResult(c.val, c.err)
+}
+
+// Records commands instead of sending them. Every command returns a nil
+// result, which the result helpers turn into zero values.
+type queue struct {
+	cmds []*Cmd
+}
+
+func (q *queue) Read(ctx context.Context, req client.Request) (interface{}, error) {
+	q.cmds = append(q.cmds, &Cmd{args: req.Path})
+	return nil, nil
+}
+
+func (q *queue) Write(ctx context.Context, req client.Request) (interface{}, error) {
+	if _, ok := req.Body.([]byte); ok {
+		return nil, fmt.Errorf("Binary values can not be sent in a pipeline")
+	}
+	body, ok := req.Body.([]string)
+	if !ok {
+		return nil, fmt.Errorf("Unable to queue request body: %v", req.Body)
+	}
+	q.cmds = append(q.cmds, &Cmd{args: body})
+	return nil, nil
+}
+
+func (q *queue) Pipeline(ctx context.Context, req client.Request) ([]client.Response, error) {
+	return nil, fmt.Errorf("Pipelines can not be nested")
+}
+
+// Pipeline queues commands and sends them to Upstash in a single request
+// when Exec is called. This saves a roundtrip per command, but the commands
+// are not executed atomically.
+//
+// Pipeline has the same methods as Upstash. Queued commands return zero
+// values, their results are returned by Exec in the same order.
+//
+//	p := u.Pipeline()
+//	p.Incr(ctx, "counter")
+//	p.Get(ctx, "key")
+//	cmds, err := p.Exec(ctx)
+//
+// A Pipeline is not safe for concurrent use.
+type Pipeline struct {
+	Upstash
+	queue  *queue
+	target client.Client
+}
+
+// Creates a new pipeline, see Pipeline.
+func (u *Upstash) Pipeline() *Pipeline {
+	q := &queue{}
+	return &Pipeline{
+		Upstash: Upstash{client: q, queued: true},
+		queue:   q,
+		target:  u.client,
+	}
+}
+
+// Queues commands in fn and executes them, see Pipeline. Nothing is sent
+// when fn returns an error.
+func (u *Upstash) Pipelined(ctx context.Context, fn func(p *Pipeline) error) ([]*Cmd, error) {
+	p := u.Pipeline()
+	if err := fn(p); err != nil {
+		p.Discard()
+		return nil, err
+	}
+	return p.Exec(ctx)
+}
+
+// Returns the number of queued commands.
+func (p *Pipeline) Len() int {
+	return len(p.queue.cmds)
+}
+
+// Removes all queued commands.
+func (p *Pipeline) Discard() {
+	p.queue.cmds = nil
+}
+
+// Sends all queued commands in a single request and clears the queue.
+//
+// Returns one Cmd per queued command. An error is only returned if the
+// request itself failed, errors of single commands are returned by their
+// Cmd.Err.
+func (p *Pipeline) Exec(ctx context.Context) ([]*Cmd, error) {
+	return p.send(ctx, "pipeline")
+}
+
+// Sends all queued commands to endpoint and clears the queue.
+func (p *Pipeline) send(ctx context.Context, endpoint string) ([]*Cmd, error) {
+	cmds := p.queue.cmds
+	p.queue.cmds = nil
+	if len(cmds) == 0 {
+		return []*Cmd{}, nil
+	}
+
+	body := make([][]string, len(cmds))
+	for i, cmd := range cmds {
+		body[i] = cmd.args
+	}
+	responses, err := p.target.Pipeline(ctx, client.Request{
+		Path: []string{endpoint},
+		Body: body,
+	})
+	if err == nil && len(responses) != len(cmds) {
+		err = fmt.Errorf("Unexpected result, expected %d responses, got %d", len(cmds), len(responses))
+	}
+	if err != nil {
+		for _, cmd := range cmds {
+			cmd.err = err
+		}
+		return cmds, err
+	}
+
+	for i, res := range responses {
+		cmds[i].val = res.Result
+		cmds[i].err = res.Err()
+	}
+	return cmds, nil
+}
@@ -0,0 +1,137 @@
+package upstash_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	p := u.Pipeline()
+	err := p.Set(ctx, key, "1")
+	require.NoError(t, err)
+	n, err := p.Incr(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+	_, err = p.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Len())
+
+	cmds, err := p.Exec(ctx)
+	require.NoError(t, err)
+	require.Len(t, cmds, 3)
+	require.Equal(t, 0, p.Len())
+
+	require.Equal(t, []string{"set", key, "1"}, cmds[0].Args())
+	status, err := cmds[0].Text()
+	require.NoError(t, err)
+	require.Equal(t, "OK", status)
+
+	n, err = cmds[1].Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	got, err := cmds[2].Text()
+	require.NoError(t, err)
+	require.Equal(t, "2", got)
+}
+
+func TestPipelineWithErrors(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	p := u.Pipeline()
+	_ = p.Set(ctx, key, "value")
+	_, _ = p.Incr(ctx, key)
+	_, _ = p.StrLen(ctx, key)
+
+	cmds, err := p.Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cmds[0].Err())
+	require.Error(t, cmds[1].Err())
+
+	length, err := cmds[2].Int()
+	require.NoError(t, err)
+	require.Equal(t, 5, length)
+}
+
+func TestPipelineResults(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	cmds, err := u.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		_, _ = p.HSet(ctx, key, map[string]string{"a": "1", "b": "2"})
+		_, _ = p.HGetAll(ctx, key)
+		_, _ = p.HKeys(ctx, key)
+		_, _ = p.HIncrByFloat(ctx, key, "a", 0.5)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, cmds, 4)
+
+	m, err := cmds[1].StringMap()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1", "b": "2"}, m)
+
+	keys, err := cmds[2].StringSlice()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, keys)
+
+	f, err := cmds[3].Float64()
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+}
+
+func TestPipelined_Error(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	failed := errors.New("failed")
+	_, err := u.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		_ = p.Set(ctx, key, "value")
+		return failed
+	})
+	require.Equal(t, failed, err)
+
+	got, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", got)
+}
+
+func TestPipelineEmpty(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	cmds, err := u.Pipeline().Exec(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cmds)
+}
+
+func TestPipelineNil(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	p := u.Pipeline()
+	_, err := p.Get(ctx, key)
+	require.NoError(t, err)
+
+	cmds, err := p.Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cmds[0].Err())
+	require.Nil(t, cmds[0].Val())
+
+	_, err = cmds[0].Text()
+	require.ErrorIs(t, err, upstash.ErrNil)
+}
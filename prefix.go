@@ -0,0 +1,412 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Returns a view of the database in which every key is prefixed with
+// prefix, e.g. to share one database between multiple services. Keys are
+// prefixed in requests and the prefix is removed from the keys returned by
+// KEYS and SCAN.
+//
+//	users := u.WithPrefix("users:")
+//	users.Set(ctx, "1", "Alice") // SET users:1 Alice
+//
+// KEYS and SCAN only return keys with the prefix. RANDOMKEY is not
+// supported, because it picks from the whole database. Keys returned by
+// scripts are not modified.
+//
+// Commands are rejected with an error when the position of their keys is
+// not known, e.g. new commands sent with Do, or when they affect all
+// prefixes, like FLUSHALL and RANDOMKEY.
+//
+// Create pipelines and transactions from the returned view, their commands
+// are prefixed as well. Calling WithPrefix on a Pipeline or Tx is not
+// supported, all commands of the returned view fail.
+func (u *Upstash) WithPrefix(prefix string) Upstash {
+	c := &prefixClient{prefix: prefix, next: u.client}
+	if u.queued {
+		c.err = fmt.Errorf("WithPrefix can not be used on a pipeline, create the pipeline from the prefixed view instead")
+	}
+	return Upstash{client: c, queued: u.queued}
+}
+
+// Prefixes the keys of every command before passing it to next.
+type prefixClient struct {
+	prefix string
+	next   client.Client
+
+	// Returned for every command, if set
+	err error
+}
+
+func (c *prefixClient) Read(ctx context.Context, req client.Request) (interface{}, error) {
+	path, err := c.command(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.next.Read(ctx, client.Request{Path: path})
+	return c.result(path, res, err)
+}
+
+func (c *prefixClient) Write(ctx context.Context, req client.Request) (interface{}, error) {
+	switch body := req.Body.(type) {
+	case []string:
+		command, err := c.command(body)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.next.Write(ctx, client.Request{Body: command})
+		return c.result(command, res, err)
+	case []byte:
+		// The raw body is the last argument and never a key
+		path, err := c.command(req.Path)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.next.Write(ctx, client.Request{Path: path, Body: body})
+		return c.result(path, res, err)
+	}
+	return nil, fmt.Errorf("Unable to prefix request body: %v", req.Body)
+}
+
+func (c *prefixClient) Pipeline(ctx context.Context, req client.Request) ([]client.Response, error) {
+	body, ok := req.Body.([][]string)
+	if !ok {
+		return nil, fmt.Errorf("Unable to prefix request body: %v", req.Body)
+	}
+	commands := make([][]string, len(body))
+	for i, command := range body {
+		var err error
+		if commands[i], err = c.command(command); err != nil {
+			return nil, err
+		}
+	}
+	responses, err := c.next.Pipeline(ctx, client.Request{Path: req.Path, Body: commands})
+	if err != nil {
+		return nil, err
+	}
+	for i := range responses {
+		if i < len(commands) && responses[i].Error == "" {
+			responses[i].Result, _ = c.result(commands[i], responses[i].Result, nil)
+		}
+	}
+	return responses, nil
+}
+
+// Returns a copy of args with the prefix added to every key.
+func (c *prefixClient) command(args []string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(args) == 0 {
+		return args, nil
+	}
+	prefixed := append([]string{}, args...)
+	name := strings.ToLower(args[0])
+	switch name {
+	case "flushall", "flushdb":
+		return nil, fmt.Errorf("%s can not be used with a prefix", strings.ToUpper(name))
+	case "keys":
+		if len(args) > 1 {
+			prefixed[1] = escapePattern(c.prefix) + args[1]
+		}
+		return prefixed, nil
+	case "scan":
+		return c.scan(prefixed), nil
+	}
+	spec, ok := keySpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%s can not be used with a prefix", strings.ToUpper(name))
+	}
+	for _, i := range spec(args) {
+		prefixed[i] = c.prefix + args[i]
+	}
+	return prefixed, nil
+}
+
+// Prefix the MATCH pattern of a SCAN command, or add one to only return
+// keys with the prefix.
+func (c *prefixClient) scan(args []string) []string {
+	for i := 2; i < len(args)-1; i++ {
+		if strings.EqualFold(args[i], "match") {
+			args[i+1] = escapePattern(c.prefix) + args[i+1]
+			return args
+		}
+	}
+	return append(args, "match", escapePattern(c.prefix)+"*")
+}
+
+// Removes the prefix from the keys returned by KEYS and SCAN.
+func (c *prefixClient) result(command []string, res interface{}, err error) (interface{}, error) {
+	if err != nil || len(command) == 0 {
+		return res, err
+	}
+	switch strings.ToLower(command[0]) {
+	case "keys":
+		return c.strip(res), nil
+	case "scan":
+		if values, ok := res.([]interface{}); ok && len(values) == 2 {
+			return []interface{}{values[0], c.strip(values[1])}, nil
+		}
+	}
+	return res, nil
+}
+
+// Remove the prefix from a key or a list of keys.
+func (c *prefixClient) strip(res interface{}) interface{} {
+	switch res := res.(type) {
+	case string:
+		return strings.TrimPrefix(res, c.prefix)
+	case []interface{}:
+		keys := make([]interface{}, len(res))
+		for i, key := range res {
+			keys[i] = c.strip(key)
+		}
+		return keys
+	}
+	return res
+}
+
+// Escape the glob characters of s, so it only matches itself in a pattern.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Returns the indexes of the keys in the arguments of a command.
+type keySpec func(args []string) []int
+
+var (
+	noKeys   keySpec = func(args []string) []int { return nil }
+	firstKey keySpec = func(args []string) []int { return span(1, min(2, len(args))) }
+	allKeys  keySpec = func(args []string) []int { return span(1, len(args)) }
+	firstTwo keySpec = func(args []string) []int { return span(1, min(3, len(args))) }
+
+	// Subcommand followed by a key, e.g. OBJECT ENCODING key
+	secondKey keySpec = func(args []string) []int { return span(2, min(3, len(args))) }
+
+	// Key value pairs, e.g. MSET key value key value
+	pairs keySpec = func(args []string) []int {
+		positions := []int{}
+		for i := 1; i < len(args); i += 2 {
+			positions = append(positions, i)
+		}
+		return positions
+	}
+
+	// The number of keys is the first argument, e.g. ZUNION 2 a b
+	numKeysFirst keySpec = func(args []string) []int { return numKeys(args, 1) }
+
+	// The number of keys follows the script, e.g. EVAL script 1 key
+	numKeysSecond keySpec = func(args []string) []int { return numKeys(args, 2) }
+
+	// The number of keys follows the destination, e.g. ZUNIONSTORE dest 2 a b
+	destinationNumKeys keySpec = func(args []string) []int {
+		return append(span(1, min(2, len(args))), numKeys(args, 2)...)
+	}
+
+	// The operation is followed by the keys, e.g. BITOP AND dest a b
+	afterOperation keySpec = func(args []string) []int { return span(2, len(args)) }
+)
+
+// The position of the keys of every supported command. Commands that are
+// missing can not be used with a prefix, because their keys would not be
+// prefixed.
+var keySpecs = map[string]keySpec{
+	"echo":   noKeys,
+	"ping":   noKeys,
+	"script": noKeys,
+	"time":   noKeys,
+
+	// Keys
+	"copy":        firstTwo,
+	"del":         allKeys,
+	"exists":      allKeys,
+	"expire":      firstKey,
+	"expireat":    firstKey,
+	"expiretime":  firstKey,
+	"memory":      secondKey,
+	"object":      secondKey,
+	"persist":     firstKey,
+	"pexpire":     firstKey,
+	"pexpireat":   firstKey,
+	"pexpiretime": firstKey,
+	"pttl":        firstKey,
+	"rename":      firstTwo,
+	"renamenx":    firstTwo,
+	"touch":       allKeys,
+	"ttl":         firstKey,
+	"type":        firstKey,
+	"unlink":      allKeys,
+
+	// Strings
+	"append":      firstKey,
+	"bitcount":    firstKey,
+	"bitop":       afterOperation,
+	"bitpos":      firstKey,
+	"decr":        firstKey,
+	"decrby":      firstKey,
+	"get":         firstKey,
+	"getbit":      firstKey,
+	"getdel":      firstKey,
+	"getex":       firstKey,
+	"getrange":    firstKey,
+	"getset":      firstKey,
+	"incr":        firstKey,
+	"incrby":      firstKey,
+	"incrbyfloat": firstKey,
+	"lcs":         firstTwo,
+	"mget":        allKeys,
+	"mset":        pairs,
+	"msetnx":      pairs,
+	"psetex":      firstKey,
+	"set":         firstKey,
+	"setbit":      firstKey,
+	"setex":       firstKey,
+	"setnx":       firstKey,
+	"setrange":    firstKey,
+	"strlen":      firstKey,
+
+	// Hashes
+	"hdel":         firstKey,
+	"hexists":      firstKey,
+	"hget":         firstKey,
+	"hgetall":      firstKey,
+	"hincrby":      firstKey,
+	"hincrbyfloat": firstKey,
+	"hkeys":        firstKey,
+	"hlen":         firstKey,
+	"hmget":        firstKey,
+	"hmset":        firstKey,
+	"hrandfield":   firstKey,
+	"hscan":        firstKey,
+	"hset":         firstKey,
+	"hsetnx":       firstKey,
+	"hstrlen":      firstKey,
+	"hvals":        firstKey,
+
+	// Lists
+	"lindex":    firstKey,
+	"linsert":   firstKey,
+	"llen":      firstKey,
+	"lmove":     firstTwo,
+	"lmpop":     numKeysFirst,
+	"lpop":      firstKey,
+	"lpos":      firstKey,
+	"lpush":     firstKey,
+	"lpushx":    firstKey,
+	"lrange":    firstKey,
+	"lrem":      firstKey,
+	"lset":      firstKey,
+	"ltrim":     firstKey,
+	"rpop":      firstKey,
+	"rpoplpush": firstTwo,
+	"rpush":     firstKey,
+	"rpushx":    firstKey,
+
+	// Sets
+	"sadd":        firstKey,
+	"scard":       firstKey,
+	"sdiff":       allKeys,
+	"sdiffstore":  allKeys,
+	"sinter":      allKeys,
+	"sintercard":  numKeysFirst,
+	"sinterstore": allKeys,
+	"sismember":   firstKey,
+	"smembers":    firstKey,
+	"smismember":  firstKey,
+	"smove":       firstTwo,
+	"spop":        firstKey,
+	"srandmember": firstKey,
+	"srem":        firstKey,
+	"sscan":       firstKey,
+	"sunion":      allKeys,
+	"sunionstore": allKeys,
+
+	// Sorted sets
+	"zadd":             firstKey,
+	"zcard":            firstKey,
+	"zcount":           firstKey,
+	"zdiff":            numKeysFirst,
+	"zdiffstore":       destinationNumKeys,
+	"zincrby":          firstKey,
+	"zinter":           numKeysFirst,
+	"zintercard":       numKeysFirst,
+	"zinterstore":      destinationNumKeys,
+	"zlexcount":        firstKey,
+	"zmpop":            numKeysFirst,
+	"zmscore":          firstKey,
+	"zpopmax":          firstKey,
+	"zpopmin":          firstKey,
+	"zrandmember":      firstKey,
+	"zrange":           firstKey,
+	"zrangebylex":      firstKey,
+	"zrangebyscore":    firstKey,
+	"zrangestore":      firstTwo,
+	"zrank":            firstKey,
+	"zrem":             firstKey,
+	"zremrangebylex":   firstKey,
+	"zremrangebyrank":  firstKey,
+	"zremrangebyscore": firstKey,
+	"zrevrange":        firstKey,
+	"zrevrangebylex":   firstKey,
+	"zrevrangebyscore": firstKey,
+	"zrevrank":         firstKey,
+	"zscan":            firstKey,
+	"zscore":           firstKey,
+	"zunion":           numKeysFirst,
+	"zunionstore":      destinationNumKeys,
+
+	// HyperLogLog
+	"pfadd":   firstKey,
+	"pfcount": allKeys,
+	"pfmerge": allKeys,
+
+	// Scripting
+	"eval":       numKeysSecond,
+	"eval_ro":    numKeysSecond,
+	"evalsha":    numKeysSecond,
+	"evalsha_ro": numKeysSecond,
+}
+
+// Returns the positions of the keys of a command that has the number of keys
+// at index i, followed by the keys.
+func numKeys(args []string, i int) []int {
+	if i >= len(args) {
+		return nil
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil || n < 0 {
+		return nil
+	}
+	return span(i+1, min(i+1+n, len(args)))
+}
+
+// Returns the integers from start up to, but not including, end.
+func span(start int, end int) []int {
+	positions := []int{}
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
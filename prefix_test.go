@@ -0,0 +1,183 @@
+package upstash_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/chronark/upstash-go/client/clienttest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPrefix(t *testing.T) {
+	prefix := uuid.NewString() + ":"
+	u, _ := upstash.New(upstash.Options{})
+	p := u.WithPrefix(prefix)
+	ctx := context.Background()
+
+	err := p.Set(ctx, "a", "1")
+	require.NoError(t, err)
+
+	got, err := u.Get(ctx, prefix+"a")
+	require.NoError(t, err)
+	require.Equal(t, "1", got)
+
+	got, err = p.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", got)
+
+	err = p.MSet(ctx, []upstash.KV{{Key: "b", Value: "2"}, {Key: "c", Value: "3"}})
+	require.NoError(t, err)
+
+	values, err := u.MGet(ctx, []string{prefix + "b", prefix + "c"})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.NullString{{Value: "2", Valid: true}, {Value: "3", Valid: true}}, values)
+
+	keys, err := p.Keys(ctx, "*")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b", "c"}, keys)
+
+	err = p.Rename(ctx, "c", "d")
+	require.NoError(t, err)
+
+	n, err := p.Del(ctx, []string{"a", "b", "d"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+}
+
+func TestWithPrefixScan(t *testing.T) {
+	prefix := uuid.NewString() + ":"
+	u, _ := upstash.New(upstash.Options{})
+	p := u.WithPrefix(prefix)
+	ctx := context.Background()
+
+	err := p.MSet(ctx, []upstash.KV{{Key: "a1", Value: "1"}, {Key: "a2", Value: "2"}, {Key: "b", Value: "3"}})
+	require.NoError(t, err)
+
+	keys := []string{}
+	it := p.ScanIterator(ctx, upstash.ScanOptions{})
+	for it.Next() {
+		keys = append(keys, it.Val())
+	}
+	require.NoError(t, it.Err())
+	require.ElementsMatch(t, []string{"a1", "a2", "b"}, keys)
+
+	keys = []string{}
+	it = p.ScanIterator(ctx, upstash.ScanOptions{Match: "a*"})
+	for it.Next() {
+		keys = append(keys, it.Val())
+	}
+	require.NoError(t, it.Err())
+	require.ElementsMatch(t, []string{"a1", "a2"}, keys)
+
+	err = p.FlushAll(ctx)
+	require.Error(t, err)
+}
+
+func TestWithPrefixPipeline(t *testing.T) {
+	prefix := uuid.NewString() + ":"
+	u, _ := upstash.New(upstash.Options{})
+	view := u.WithPrefix(prefix)
+	ctx := context.Background()
+
+	cmds, err := view.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		_ = p.Set(ctx, "a", "1")
+		_, _ = p.Keys(ctx, "*")
+		return nil
+	})
+	require.NoError(t, err)
+	keys, err := cmds[1].StringSlice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, keys)
+
+	got, err := u.Get(ctx, prefix+"a")
+	require.NoError(t, err)
+	require.Equal(t, "1", got)
+}
+
+func TestWithPrefixCommands(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On().Return(nil)
+	u := upstash.NewWithClient(m)
+	p := u.WithPrefix("p:")
+	ctx := context.Background()
+
+	_ = p.MSet(ctx, []upstash.KV{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}})
+	_, _ = p.Copy(ctx, "a", "b", true)
+	_, _ = p.Eval(ctx, "return 1", []string{"a"}, []string{"b"})
+	_, _ = p.ZUnionStore(ctx, "dest", []string{"a", "b"}, upstash.ZStoreOptions{})
+	_ = p.SetBytes(ctx, "a", []byte("value"))
+	_, _ = p.Keys(ctx, "*")
+	_, _, _ = p.Scan(ctx, 0, upstash.ScanOptions{})
+	_ = p.Do(ctx, "hset", "h", "field", "value")
+
+	m.AssertCommands(t,
+		[]string{"mset", "p:a", "1", "p:b", "2"},
+		[]string{"copy", "p:a", "p:b", "replace"},
+		[]string{"eval", "return 1", "1", "p:a", "b"},
+		[]string{"zunionstore", "p:dest", "2", "p:a", "p:b"},
+		[]string{"set", "p:a", "value"},
+		[]string{"keys", "p:*"},
+		[]string{"scan", "0", "match", "p:*"},
+		[]string{"hset", "p:h", "field", "value"},
+	)
+}
+
+func TestWithPrefixKeyPositions(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On().Return(nil)
+	u := upstash.NewWithClient(m)
+	p := u.WithPrefix("p:")
+	ctx := context.Background()
+
+	_ = p.Do(ctx, "object", "encoding", "a")
+	_ = p.Do(ctx, "memory", "usage", "a")
+	_ = p.Do(ctx, "zunion", "2", "a", "b", "withscores")
+	_ = p.Do(ctx, "sintercard", "2", "a", "b", "limit", "1")
+	_ = p.Do(ctx, "zrangestore", "dest", "a", "0", "-1")
+	_ = p.Do(ctx, "pfcount", "a", "b")
+	_ = p.Do(ctx, "pfmerge", "dest", "a", "b")
+
+	m.AssertCommands(t,
+		[]string{"object", "encoding", "p:a"},
+		[]string{"memory", "usage", "p:a"},
+		[]string{"zunion", "2", "p:a", "p:b", "withscores"},
+		[]string{"sintercard", "2", "p:a", "p:b", "limit", "1"},
+		[]string{"zrangestore", "p:dest", "p:a", "0", "-1"},
+		[]string{"pfcount", "p:a", "p:b"},
+		[]string{"pfmerge", "p:dest", "p:a", "p:b"},
+	)
+
+	err := p.Do(ctx, "unknown", "a").Err()
+	require.Error(t, err)
+	m.AssertNotCalled(t, "unknown", "a")
+}
+
+func TestWithPrefixRandomKey(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On().Return("p:a")
+	u := upstash.NewWithClient(m)
+	p := u.WithPrefix("p:")
+	ctx := context.Background()
+
+	_, err := p.RandomKey(ctx)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, upstash.ErrNil))
+	m.AssertCommands(t)
+}
+
+func TestWithPrefixOnPipeline(t *testing.T) {
+	m := clienttest.NewMock()
+	m.On().Return("OK")
+	u := upstash.NewWithClient(m)
+	ctx := context.Background()
+
+	_, err := u.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		prefixed := p.WithPrefix("p:")
+		return prefixed.Do(ctx, "set", "a", "1").Err()
+	})
+	require.Error(t, err)
+	m.AssertCommands(t)
+}
@@ -0,0 +1,286 @@
+package upstash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Returned by commands that reply with nil, e.g. Get when the key does not
+// exist. Use errors.Is(err, upstash.ErrNil) to tell a missing value apart
+// from an empty one.
+var ErrNil = errors.New("Nil reply")
+
+// The REST API returns integers as JSON numbers, which the client decodes as
+// int64, and floats as strings. These helpers convert raw results into the
+// types returned by the command methods and never panic on nil or
+// unexpected results.
+//
+// Values, counts, lengths and positions are returned as int64 so they are
+// exact on every platform. Replies that are only ever 0 or 1 are returned
+// as int.
+
+// Turn a nil reply into ErrNil, for commands where nil means that there is
+// no value. Queued commands always reply with nil and are not affected.
+func (u *Upstash) nilResult(res interface{}, err error) (interface{}, error) {
+	if err == nil && res == nil && !u.queued {
+		return nil, ErrNil
+	}
+	return res, err
+}
+
+func intResult(res interface{}, err error) (int, error) {
+	n, err := int64Result(res, err)
+	return int(n), err
+}
+
+func int64Result(res interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch res := res.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return res, nil
+	case json.Number:
+		return res.Int64()
+	}
+	return 0, fmt.Errorf("Unexpected result, expected integer: %v", res)
+}
+
+func boolResult(res interface{}, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	switch res := res.(type) {
+	case nil:
+		return false, nil
+	case int64:
+		return res != 0, nil
+	case string:
+		if res == "OK" {
+			return true, nil
+		}
+		b, err := strconv.ParseBool(res)
+		if err != nil {
+			return false, fmt.Errorf("Unexpected result, expected boolean: %v", res)
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("Unexpected result, expected boolean: %v", res)
+}
+
+func stringResult(res interface{}, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return "", nil
+	}
+	s, ok := res.(string)
+	if !ok {
+		return "", fmt.Errorf("Unexpected result, expected string: %v", res)
+	}
+	return s, nil
+}
+
+func bytesResult(res interface{}, err error) ([]byte, error) {
+	s, err := stringResult(res, err)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func floatResult(res interface{}, err error) (float64, error) {
+	if n, ok := res.(int64); ok && err == nil {
+		return float64(n), nil
+	}
+	s, err := stringResult(res, err)
+	if err != nil || s == "" {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func sliceResult(res interface{}, err error) ([]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []interface{}{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected result, expected array: %v", res)
+	}
+	return values, nil
+}
+
+func stringSliceResult(res interface{}, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []string{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected result, expected array: %v", res)
+	}
+	strings := make([]string, len(values))
+	for i, value := range values {
+		if strings[i], err = stringResult(value, nil); err != nil {
+			return nil, err
+		}
+	}
+	return strings, nil
+}
+
+func intSliceResult(res interface{}, err error) ([]int, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []int{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected result, expected array: %v", res)
+	}
+	ints := make([]int, len(values))
+	for i, value := range values {
+		if ints[i], err = intResult(value, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ints, nil
+}
+
+func int64SliceResult(res interface{}, err error) ([]int64, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []int64{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected result, expected array: %v", res)
+	}
+	ints := make([]int64, len(values))
+	for i, value := range values {
+		if ints[i], err = int64Result(value, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ints, nil
+}
+
+func nullStringSliceResult(res interface{}, err error) ([]NullString, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []NullString{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected result, expected array: %v", res)
+	}
+	strings := make([]NullString, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		s, err := stringResult(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		strings[i] = NullString{Value: s, Valid: true}
+	}
+	return strings, nil
+}
+
+// Convert a flat [key, value, key, value, ...] array into pairs.
+func kvSliceResult(res interface{}, err error) ([]KV, error) {
+	values, err := stringSliceResult(res, err)
+	if err != nil {
+		return nil, err
+	}
+	return toKVs(values)
+}
+
+func toKVs(values []string) ([]KV, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("Unexpected result, expected an even number of elements: %v", values)
+	}
+	pairs := make([]KV, len(values)/2)
+	for i := range pairs {
+		pairs[i] = KV{Key: values[2*i], Value: values[2*i+1]}
+	}
+	return pairs, nil
+}
+
+// Convert a flat [key, value, key, value, ...] array into a map.
+func stringMapResult(res interface{}, err error) (map[string]string, error) {
+	pairs, err := kvSliceResult(res, err)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(pairs))
+	for _, kv := range pairs {
+		m[kv.Key] = kv.Value
+	}
+	return m, nil
+}
+
+// Convert the [cursor, [elements...]] result of the SCAN family.
+func scanResult(res interface{}, err error) (uint64, []string, error) {
+	if err != nil {
+		return 0, nil, err
+	}
+	if res == nil {
+		return 0, []string{}, nil
+	}
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, nil, fmt.Errorf("Unexpected result, expected cursor and elements: %v", res)
+	}
+	c, err := stringResult(values[0], nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	cursor, err := strconv.ParseUint(c, 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Unexpected cursor: %w", err)
+	}
+	elements, err := stringSliceResult(values[1], nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	return cursor, elements, nil
+}
+
+// Convert a flat [member, score, member, score, ...] array into a sorted set.
+func zSliceResult(res interface{}, err error) ([]Z, error) {
+	pairs, err := kvSliceResult(res, err)
+	if err != nil {
+		return nil, err
+	}
+	return toZs(pairs)
+}
+
+func toZs(pairs []KV) ([]Z, error) {
+	zs := make([]Z, len(pairs))
+	for i, kv := range pairs {
+		score, err := strconv.ParseFloat(kv.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Unexpected score: %w", err)
+		}
+		zs[i] = Z{Score: score, Member: kv.Key}
+	}
+	return zs, nil
+}
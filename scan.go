@@ -0,0 +1,190 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Build the MATCH, COUNT and TYPE arguments shared by the SCAN family.
+func scanArgs(options ScanOptions) []string {
+	args := []string{}
+	if options.Match != "" {
+		args = append(args, "match", options.Match)
+	}
+	if options.Count != 0 {
+		args = append(args, "count", fmt.Sprintf("%d", options.Count))
+	}
+	if options.Type != "" {
+		args = append(args, "type", options.Type)
+	}
+	return args
+}
+
+// Iterates the set of keys in the database, starting at cursor. Start with
+// a cursor of 0 and call SCAN with the returned cursor until it returns 0
+// again. A page may be empty even though the iteration is not complete.
+//
+// Unlike KEYS, SCAN is safe to use in production because every call only
+// does a small amount of work. Elements present during the whole iteration
+// are returned at least once, but may be returned multiple times.
+//
+// Use ScanIterator to follow the cursor automatically.
+//
+// Returns the cursor for the next call and a page of keys.
+//
+// https://redis.io/commands/scan
+func (u *Upstash) Scan(ctx context.Context, cursor uint64, options ScanOptions) (uint64, []string, error) {
+	return scanResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"scan", fmt.Sprintf("%d", cursor)}, scanArgs(options)...),
+	}))
+}
+
+// Follows a scan cursor until the iteration is complete. fetch loads the
+// page for a cursor and returns the next cursor and the size of the page.
+type scanner struct {
+	fetch func(cursor uint64) (uint64, int, error)
+
+	cursor  uint64
+	started bool
+	index   int
+	size    int
+	err     error
+}
+
+func (s *scanner) next() bool {
+	for {
+		if s.err != nil {
+			return false
+		}
+		if s.index+1 < s.size {
+			s.index++
+			return true
+		}
+		if s.started && s.cursor == 0 {
+			return false
+		}
+		s.started = true
+		s.cursor, s.size, s.err = s.fetch(s.cursor)
+		s.index = -1
+	}
+}
+
+// ScanIterator iterates over the elements returned by SCAN or SSCAN,
+// transparently following the cursor until the iteration is complete.
+//
+//	iter := u.ScanIterator(ctx, upstash.ScanOptions{Match: "user:*"})
+//	for iter.Next() {
+//		fmt.Println(iter.Val())
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type ScanIterator struct {
+	scanner
+	page []string
+}
+
+// Advances the iterator, returns false when the iteration is complete or an
+// error occurred.
+func (it *ScanIterator) Next() bool {
+	return it.next()
+}
+
+// Returns the current element.
+func (it *ScanIterator) Val() string {
+	return it.page[it.index]
+}
+
+// Returns the error that stopped the iteration, if any.
+func (it *ScanIterator) Err() error {
+	return it.err
+}
+
+// HScanIterator iterates over the fields of a hash, see ScanIterator.
+type HScanIterator struct {
+	scanner
+	page []KV
+}
+
+// Advances the iterator, returns false when the iteration is complete or an
+// error occurred.
+func (it *HScanIterator) Next() bool {
+	return it.next()
+}
+
+// Returns the current field and value.
+func (it *HScanIterator) Val() KV {
+	return it.page[it.index]
+}
+
+// Returns the error that stopped the iteration, if any.
+func (it *HScanIterator) Err() error {
+	return it.err
+}
+
+// ZScanIterator iterates over the members of a sorted set, see ScanIterator.
+type ZScanIterator struct {
+	scanner
+	page []Z
+}
+
+// Advances the iterator, returns false when the iteration is complete or an
+// error occurred.
+func (it *ZScanIterator) Next() bool {
+	return it.next()
+}
+
+// Returns the current member and score.
+func (it *ZScanIterator) Val() Z {
+	return it.page[it.index]
+}
+
+// Returns the error that stopped the iteration, if any.
+func (it *ZScanIterator) Err() error {
+	return it.err
+}
+
+// Returns an iterator over all keys matching the options, using SCAN.
+func (u *Upstash) ScanIterator(ctx context.Context, options ScanOptions) *ScanIterator {
+	it := &ScanIterator{}
+	it.fetch = func(cursor uint64) (next uint64, size int, err error) {
+		next, it.page, err = u.Scan(ctx, cursor, options)
+		return next, len(it.page), err
+	}
+	return it
+}
+
+// Returns an iterator over all fields of the hash stored at key, using
+// HSCAN.
+func (u *Upstash) HScanIterator(ctx context.Context, key string, options ScanOptions) *HScanIterator {
+	it := &HScanIterator{}
+	it.fetch = func(cursor uint64) (next uint64, size int, err error) {
+		next, it.page, err = u.HScan(ctx, key, cursor, options)
+		return next, len(it.page), err
+	}
+	return it
+}
+
+// Returns an iterator over all members of the set stored at key, using
+// SSCAN.
+func (u *Upstash) SScanIterator(ctx context.Context, key string, options ScanOptions) *ScanIterator {
+	it := &ScanIterator{}
+	it.fetch = func(cursor uint64) (next uint64, size int, err error) {
+		next, it.page, err = u.SScan(ctx, key, cursor, options)
+		return next, len(it.page), err
+	}
+	return it
+}
+
+// Returns an iterator over all members of the sorted set stored at key,
+// using ZSCAN.
+func (u *Upstash) ZScanIterator(ctx context.Context, key string, options ScanOptions) *ZScanIterator {
+	it := &ZScanIterator{}
+	it.fetch = func(cursor uint64) (next uint64, size int, err error) {
+		next, it.page, err = u.ZScan(ctx, key, cursor, options)
+		return next, len(it.page), err
+	}
+	return it
+}
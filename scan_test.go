@@ -0,0 +1,149 @@
+package upstash_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	prefix := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	keys := []string{}
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("%s:%d", prefix, i)
+		err := u.Set(ctx, key, "value")
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+
+	got := []string{}
+	cursor := uint64(0)
+	for {
+		next, page, err := u.Scan(ctx, cursor, upstash.ScanOptions{Match: prefix + ":*", Count: 100})
+		require.NoError(t, err)
+		got = append(got, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	require.ElementsMatch(t, keys, got)
+}
+
+func TestScanIterator(t *testing.T) {
+	prefix := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	keys := []string{}
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("%s:%d", prefix, i)
+		err := u.Set(ctx, key, "value")
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	_, err := u.SAdd(ctx, prefix+":set", []string{"a"})
+	require.NoError(t, err)
+
+	got := []string{}
+	iter := u.ScanIterator(ctx, upstash.ScanOptions{Match: prefix + ":*", Type: "string"})
+	for iter.Next() {
+		got = append(got, iter.Val())
+	}
+	require.NoError(t, iter.Err())
+	require.ElementsMatch(t, keys, got)
+}
+
+func TestScanIteratorWithoutMatches(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	iter := u.ScanIterator(ctx, upstash.ScanOptions{Match: uuid.NewString()})
+	for iter.Next() {
+		t.Fatalf("unexpected key %s", iter.Val())
+	}
+	require.NoError(t, iter.Err())
+}
+
+func TestHScanIterator(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	values := map[string]string{}
+	for i := 0; i < 25; i++ {
+		values[uuid.NewString()] = uuid.NewString()
+	}
+	_, err := u.HSet(ctx, key, values)
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	iter := u.HScanIterator(ctx, key, upstash.ScanOptions{Count: 10})
+	for iter.Next() {
+		got[iter.Val().Key] = iter.Val().Value
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, values, got)
+}
+
+func TestSScanIterator(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	members := []string{}
+	for i := 0; i < 25; i++ {
+		members = append(members, uuid.NewString())
+	}
+	_, err := u.SAdd(ctx, key, members)
+	require.NoError(t, err)
+
+	got := []string{}
+	iter := u.SScanIterator(ctx, key, upstash.ScanOptions{})
+	for iter.Next() {
+		got = append(got, iter.Val())
+	}
+	require.NoError(t, iter.Err())
+	require.ElementsMatch(t, members, got)
+}
+
+func TestZScanIterator(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	members := []upstash.Z{}
+	for i := 0; i < 25; i++ {
+		members = append(members, upstash.Z{Score: float64(i), Member: uuid.NewString()})
+	}
+	_, err := u.ZAdd(ctx, key, members)
+	require.NoError(t, err)
+
+	got := []upstash.Z{}
+	iter := u.ZScanIterator(ctx, key, upstash.ScanOptions{})
+	for iter.Next() {
+		got = append(got, iter.Val())
+	}
+	require.NoError(t, iter.Err())
+	require.ElementsMatch(t, members, got)
+}
+
+func TestScanIteratorError(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	iter := u.SScanIterator(ctx, key, upstash.ScanOptions{})
+	require.False(t, iter.Next())
+	require.Error(t, iter.Err())
+}
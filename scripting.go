@@ -0,0 +1,146 @@
+package upstash
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+func evalArgs(command string, script string, keys []string, args []string) []string {
+	body := []string{command, script, fmt.Sprintf("%d", len(keys))}
+	body = append(body, keys...)
+	return append(body, args...)
+}
+
+// Evaluates a Lua script on the server. Keys accessed by the script must be
+// passed in keys and are available as the KEYS table, all other arguments
+// are available as the ARGV table.
+//
+// Returns the result of the script, converted from Lua to JSON: integers
+// are returned as int64, strings as string, tables as []interface{} and
+// false as nil.
+//
+// https://redis.io/commands/eval
+func (u *Upstash) Eval(ctx context.Context, script string, keys []string, args []string) (interface{}, error) {
+	return u.client.Write(ctx, client.Request{
+		Body: evalArgs("eval", script, keys, args),
+	})
+}
+
+// Same as Eval but the script is referenced by its SHA1 digest. The script
+// must have been cached on the server before, using SCRIPT LOAD or EVAL,
+// otherwise a NOSCRIPT error is returned.
+//
+// https://redis.io/commands/evalsha
+func (u *Upstash) EvalSha(ctx context.Context, sha1 string, keys []string, args []string) (interface{}, error) {
+	return u.client.Write(ctx, client.Request{
+		Body: evalArgs("evalsha", sha1, keys, args),
+	})
+}
+
+// Returns information about the existence of the scripts in the script
+// cache.
+//
+// Returns a list of integers, with 1 for every script that exists in the
+// cache and 0 otherwise.
+//
+// https://redis.io/commands/script-exists
+func (u *Upstash) ScriptExists(ctx context.Context, sha1s []string) ([]int, error) {
+	return intSliceResult(u.client.Write(ctx, client.Request{
+		Body: append([]string{"script", "exists"}, sha1s...),
+	}))
+}
+
+// Flush the Lua scripts cache.
+//
+// https://redis.io/commands/script-flush
+func (u *Upstash) ScriptFlush(ctx context.Context) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Body: []string{"script", "flush"},
+	})
+	return err
+}
+
+// Load a script into the scripts cache, without executing it. The script can
+// then be run with EVALSHA.
+//
+// Returns the SHA1 digest of the script.
+//
+// https://redis.io/commands/script-load
+func (u *Upstash) ScriptLoad(ctx context.Context, script string) (string, error) {
+	return stringResult(u.client.Write(ctx, client.Request{
+		Body: []string{"script", "load", script},
+	}))
+}
+
+// Script is a Lua script that is run with EVALSHA, so the source is only
+// sent to the server when it is not cached yet.
+//
+//	var incrBy = upstash.NewScript(`return redis.call("incrby", KEYS[1], ARGV[1])`)
+//	res, err := incrBy.Run(ctx, u, []string{"counter"}, []string{"2"})
+type Script struct {
+	src  string
+	hash string
+}
+
+// Creates a new script and computes its SHA1 digest.
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{
+		src:  src,
+		hash: hex.EncodeToString(sum[:]),
+	}
+}
+
+// Returns the SHA1 digest of the script.
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Loads the script into the scripts cache, see ScriptLoad.
+func (s *Script) Load(ctx context.Context, c Cmdable) error {
+	_, err := c.ScriptLoad(ctx, s.src)
+	return err
+}
+
+// Returns whether the script is cached on the server.
+func (s *Script) Exists(ctx context.Context, c Cmdable) (bool, error) {
+	exists, err := c.ScriptExists(ctx, []string{s.hash})
+	if err != nil {
+		return false, err
+	}
+	return len(exists) == 1 && exists[0] == 1, nil
+}
+
+// Runs the script with EVAL, sending the full source.
+func (s *Script) Eval(ctx context.Context, c Cmdable, keys []string, args []string) (interface{}, error) {
+	return c.Eval(ctx, s.src, keys, args)
+}
+
+// Runs the script with EVALSHA.
+func (s *Script) EvalSha(ctx context.Context, c Cmdable, keys []string, args []string) (interface{}, error) {
+	return c.EvalSha(ctx, s.hash, keys, args)
+}
+
+// Runs the script with EVALSHA and retries with EVAL when the script is not
+// cached on the server. EVAL caches the script, so following calls only
+// send the digest.
+//
+// Queued commands never return errors, use Eval inside a Pipeline or Tx.
+func (s *Script) Run(ctx context.Context, c Cmdable, keys []string, args []string) (interface{}, error) {
+	res, err := s.EvalSha(ctx, c, keys, args)
+	if err != nil && isNoScript(err) {
+		return s.Eval(ctx, c, keys, args)
+	}
+	return res, err
+}
+
+// Redis returns a NOSCRIPT error when the script is not cached.
+func isNoScript(err error) bool {
+	var redisErr *client.RedisError
+	return errors.As(err, &redisErr) && redisErr.Prefix == "NOSCRIPT"
+}
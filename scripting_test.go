@@ -0,0 +1,77 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	res, err := u.Eval(ctx, `return {KEYS[1], ARGV[1]}`, []string{key}, []string{"value"})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{key, "value"}, res)
+
+	_, err = u.Eval(ctx, `return redis.call("set", KEYS[1], ARGV[1])`, []string{key}, []string{"value"})
+	require.NoError(t, err)
+
+	got, err := u.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "value", got)
+}
+
+func TestScriptLoad(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+	script := upstash.NewScript(`return "` + uuid.NewString() + `"`)
+
+	exists, err := u.ScriptExists(ctx, []string{script.Hash()})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, exists)
+
+	_, err = u.EvalSha(ctx, script.Hash(), []string{}, []string{})
+	require.Error(t, err)
+
+	sha, err := u.ScriptLoad(ctx, `return "`+uuid.NewString()+`"`)
+	require.NoError(t, err)
+	require.Len(t, sha, 40)
+
+	err = script.Load(ctx, &u)
+	require.NoError(t, err)
+
+	ok, err := script.Exists(ctx, &u)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = script.EvalSha(ctx, &u, []string{}, []string{})
+	require.NoError(t, err)
+}
+
+func TestScriptRun(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+	script := upstash.NewScript(`return redis.call("incrby", KEYS[1], ARGV[1]) -- ` + uuid.NewString())
+
+	ok, err := script.Exists(ctx, &u)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	res, err := script.Run(ctx, &u, []string{key}, []string{"2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res)
+
+	ok, err = script.Exists(ctx, &u)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	res, err = script.Run(ctx, &u, []string{key}, []string{"3"})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res)
+}
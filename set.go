@@ -0,0 +1,229 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Add the specified members to the set stored at key. Specified members that
+// are already a member of this set are ignored. If key does not exist, a new
+// set is created before adding the specified members.
+//
+// Returns the number of elements that were added to the set, not including
+// all the elements already present in the set.
+//
+// https://redis.io/commands/sadd
+func (u *Upstash) SAdd(ctx context.Context, key string, members []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"sadd", key}, members...),
+	}))
+}
+
+// Returns the set cardinality (number of elements) of the set stored at key.
+//
+// Returns the cardinality of the set, or 0 when key does not exist.
+//
+// https://redis.io/commands/scard
+func (u *Upstash) SCard(ctx context.Context, key string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"scard", key},
+	}))
+}
+
+// Returns the members of the set resulting from the difference between the
+// first set and all the successive sets. Keys that do not exist are
+// considered to be empty sets.
+//
+// Returns the members of the resulting set.
+//
+// https://redis.io/commands/sdiff
+func (u *Upstash) SDiff(ctx context.Context, keys []string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"sdiff"}, keys...),
+	}))
+}
+
+// This command is equal to SDIFF, but instead of returning the resulting
+// set, it is stored in destination. If destination already exists, it is
+// overwritten.
+//
+// Returns the number of elements in the resulting set.
+//
+// https://redis.io/commands/sdiffstore
+func (u *Upstash) SDiffStore(ctx context.Context, destination string, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"sdiffstore", destination}, keys...),
+	}))
+}
+
+// Returns the members of the set resulting from the intersection of all the
+// given sets. Keys that do not exist are considered to be empty sets, so
+// the result is empty as soon as one key is missing.
+//
+// Returns the members of the resulting set.
+//
+// https://redis.io/commands/sinter
+func (u *Upstash) SInter(ctx context.Context, keys []string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"sinter"}, keys...),
+	}))
+}
+
+// This command is equal to SINTER, but instead of returning the resulting
+// set, it is stored in destination. If destination already exists, it is
+// overwritten.
+//
+// Returns the number of elements in the resulting set.
+//
+// https://redis.io/commands/sinterstore
+func (u *Upstash) SInterStore(ctx context.Context, destination string, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"sinterstore", destination}, keys...),
+	}))
+}
+
+// Returns if member is a member of the set stored at key.
+//
+// Return:
+// - 1 if the element is a member of the set
+// - 0 if the element is not a member of the set, or key does not exist
+//
+// https://redis.io/commands/sismember
+func (u *Upstash) SIsMember(ctx context.Context, key string, member string) (int, error) {
+	return intResult(u.client.Read(ctx, client.Request{
+		Path: []string{"sismember", key, member},
+	}))
+}
+
+// Returns all the members of the set value stored at key.
+//
+// Returns all elements of the set, or an empty list when key does not
+// exist.
+//
+// https://redis.io/commands/smembers
+func (u *Upstash) SMembers(ctx context.Context, key string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"smembers", key},
+	}))
+}
+
+// Returns whether each member is a member of the set stored at key.
+//
+// Returns a list of 1 or 0 for every member, in the same order as they are
+// requested.
+//
+// https://redis.io/commands/smismember
+func (u *Upstash) SMIsMember(ctx context.Context, key string, members []string) ([]int, error) {
+	return intSliceResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"smismember", key}, members...),
+	}))
+}
+
+// Move member from the set at source to the set at destination. This
+// operation is atomic.
+//
+// Return:
+// - 1 if the element is moved
+// - 0 if the element is not a member of source and no operation was performed
+//
+// https://redis.io/commands/smove
+func (u *Upstash) SMove(ctx context.Context, source string, destination string, member string) (int, error) {
+	return intResult(u.client.Write(ctx, client.Request{
+		Body: []string{"smove", source, destination, member},
+	}))
+}
+
+// Removes and returns a random member from the set value stored at key.
+//
+// Returns the removed member, or ErrNil when key does not exist.
+//
+// https://redis.io/commands/spop
+func (u *Upstash) SPop(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"spop", key},
+	})))
+}
+
+// Same as SPop but removes and returns up to count members.
+//
+// https://redis.io/commands/spop
+func (u *Upstash) SPopCount(ctx context.Context, key string, count int) ([]string, error) {
+	return stringSliceResult(u.client.Write(ctx, client.Request{
+		Body: []string{"spop", key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+// Returns a random member from the set value stored at key.
+//
+// Returns the randomly selected member, or ErrNil when key does not exist.
+//
+// https://redis.io/commands/srandmember
+func (u *Upstash) SRandMember(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"srandmember", key},
+	})))
+}
+
+// Same as SRandMember but returns up to count distinct members. If count is
+// negative, the same member may be returned multiple times and exactly the
+// absolute value of count members are returned.
+//
+// https://redis.io/commands/srandmember
+func (u *Upstash) SRandMemberWithCount(ctx context.Context, key string, count int) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"srandmember", key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+// Remove the specified members from the set stored at key. Specified members
+// that are not a member of this set are ignored.
+//
+// Returns the number of members that were removed from the set, not
+// including non existing members.
+//
+// https://redis.io/commands/srem
+func (u *Upstash) SRem(ctx context.Context, key string, members []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"srem", key}, members...),
+	}))
+}
+
+// Iterates members of the set stored at key, starting at cursor. Start with
+// a cursor of 0 and call SSCAN with the returned cursor until it returns 0
+// again.
+//
+// Returns the cursor for the next call and a page of members.
+//
+// https://redis.io/commands/sscan
+func (u *Upstash) SScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []string, error) {
+	return scanResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"sscan", key, fmt.Sprintf("%d", cursor)}, scanArgs(options)...),
+	}))
+}
+
+// Returns the members of the set resulting from the union of all the given
+// sets. Keys that do not exist are considered to be empty sets.
+//
+// Returns the members of the resulting set.
+//
+// https://redis.io/commands/sunion
+func (u *Upstash) SUnion(ctx context.Context, keys []string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"sunion"}, keys...),
+	}))
+}
+
+// This command is equal to SUNION, but instead of returning the resulting
+// set, it is stored in destination. If destination already exists, it is
+// overwritten.
+//
+// Returns the number of elements in the resulting set.
+//
+// https://redis.io/commands/sunionstore
+func (u *Upstash) SUnionStore(ctx context.Context, destination string, keys []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"sunionstore", destination}, keys...),
+	}))
+}
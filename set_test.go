@@ -0,0 +1,210 @@
+package upstash_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAdd(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	added, err := u.SAdd(ctx, key, []string{"a", "b", "a"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), added)
+
+	members, err := u.SMembers(ctx, key)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, members)
+
+	cardinality, err := u.SCard(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cardinality)
+}
+
+func TestSRem(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	removed, err := u.SRem(ctx, key, []string{"a", "b", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), removed)
+
+	members, err := u.SMembers(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, members)
+}
+
+func TestSIsMember(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key, []string{"a", "b"})
+	require.NoError(t, err)
+
+	isMember, err := u.SIsMember(ctx, key, "a")
+	require.NoError(t, err)
+	require.Equal(t, 1, isMember)
+
+	isMember, err = u.SIsMember(ctx, key, "c")
+	require.NoError(t, err)
+	require.Equal(t, 0, isMember)
+
+	areMembers, err := u.SMIsMember(ctx, key, []string{"b", "c", "a"})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 0, 1}, areMembers)
+}
+
+func TestSetAlgebra(t *testing.T) {
+	key1 := uuid.NewString()
+	key2 := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key1, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	_, err = u.SAdd(ctx, key2, []string{"c", "d"})
+	require.NoError(t, err)
+
+	inter, err := u.SInter(ctx, []string{key1, key2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, inter)
+
+	union, err := u.SUnion(ctx, []string{key1, key2})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b", "c", "d"}, union)
+
+	diff, err := u.SDiff(ctx, []string{key1, key2})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, diff)
+}
+
+func TestSetAlgebraStore(t *testing.T) {
+	key1 := uuid.NewString()
+	key2 := uuid.NewString()
+	destination := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key1, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	_, err = u.SAdd(ctx, key2, []string{"c", "d"})
+	require.NoError(t, err)
+
+	n, err := u.SInterStore(ctx, destination, []string{key1, key2})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = u.SUnionStore(ctx, destination, []string{key1, key2})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+
+	n, err = u.SDiffStore(ctx, destination, []string{key1, key2})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	members, err := u.SMembers(ctx, destination)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, members)
+}
+
+func TestSMove(t *testing.T) {
+	source := uuid.NewString()
+	destination := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, source, []string{"a", "b"})
+	require.NoError(t, err)
+
+	moved, err := u.SMove(ctx, source, destination, "a")
+	require.NoError(t, err)
+	require.Equal(t, 1, moved)
+
+	moved, err = u.SMove(ctx, source, destination, "missing")
+	require.NoError(t, err)
+	require.Equal(t, 0, moved)
+
+	members, err := u.SMembers(ctx, destination)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, members)
+}
+
+func TestSRandMember(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	member, err := u.SRandMember(ctx, key)
+	require.NoError(t, err)
+	require.Contains(t, []string{"a", "b", "c"}, member)
+
+	members, err := u.SRandMemberWithCount(ctx, key, -5)
+	require.NoError(t, err)
+	require.Len(t, members, 5)
+
+	cardinality, err := u.SCard(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), cardinality)
+}
+
+func TestSPop(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.SAdd(ctx, key, []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	member, err := u.SPop(ctx, key)
+	require.NoError(t, err)
+	require.Contains(t, []string{"a", "b", "c"}, member)
+
+	members, err := u.SPopCount(ctx, key, 5)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.NotContains(t, members, member)
+
+	member, err = u.SPop(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", member)
+}
+
+func TestSScan(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	members := []string{}
+	for i := 0; i < 20; i++ {
+		members = append(members, uuid.NewString())
+	}
+	_, err := u.SAdd(ctx, key, members)
+	require.NoError(t, err)
+
+	got := []string{}
+	cursor := uint64(0)
+	for {
+		next, page, err := u.SScan(ctx, key, cursor, upstash.ScanOptions{Count: 5})
+		require.NoError(t, err)
+		got = append(got, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	require.ElementsMatch(t, members, got)
+}
@@ -0,0 +1,324 @@
+package upstash
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// Adds all the specified members with the specified scores to the sorted set
+// stored at key. If a specified member is already a member of the sorted
+// set, the score is updated and the element reinserted at the right position
+// to ensure the correct ordering. If key does not exist, a new sorted set
+// with the specified members as sole members is created.
+//
+// Returns the number of elements added to the sorted set, not including
+// elements already existing for which the score was updated.
+//
+// https://redis.io/commands/zadd
+func (u *Upstash) ZAdd(ctx context.Context, key string, members []Z) (int64, error) {
+	return u.ZAddWithOptions(ctx, key, members, ZAddOptions{})
+}
+
+func zaddArgs(key string, options ZAddOptions) []string {
+	args := []string{"zadd", key}
+	if options.NX {
+		args = append(args, "nx")
+	} else if options.XX {
+		args = append(args, "xx")
+	}
+	if options.GT {
+		args = append(args, "gt")
+	} else if options.LT {
+		args = append(args, "lt")
+	}
+	if options.CH {
+		args = append(args, "ch")
+	}
+	return args
+}
+
+// Same as ZAdd but with additional options.
+//
+// Returns the number of elements added, or the number of elements changed
+// when the CH option is set.
+//
+// https://redis.io/commands/zadd
+func (u *Upstash) ZAddWithOptions(ctx context.Context, key string, members []Z, options ZAddOptions) (int64, error) {
+	body := zaddArgs(key, options)
+	for _, z := range members {
+		body = append(body, formatScore(z.Score), z.Member)
+	}
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: body,
+	}))
+}
+
+// Increments the score of member like ZINCRBY, using ZADD with the INCR
+// option so the increment can be combined with the other options.
+//
+// Returns the new score of member, or ErrNil when the operation was aborted
+// because of a conflict with the NX, XX, GT or LT options.
+//
+// https://redis.io/commands/zadd
+func (u *Upstash) ZAddIncr(ctx context.Context, key string, member Z, options ZAddOptions) (float64, error) {
+	body := append(zaddArgs(key, options), "incr", formatScore(member.Score), member.Member)
+	return floatResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: body,
+	})))
+}
+
+// Returns the sorted set cardinality (number of elements) of the sorted set
+// stored at key.
+//
+// Returns the cardinality of the sorted set, or 0 when key does not exist.
+//
+// https://redis.io/commands/zcard
+func (u *Upstash) ZCard(ctx context.Context, key string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"zcard", key},
+	}))
+}
+
+// Returns the number of elements in the sorted set at key with a score
+// between min and max. Both bounds are inclusive by default, prefix them
+// with "(" to make them exclusive. Use "-inf" and "+inf" for unbounded
+// ranges.
+//
+// Returns the number of elements in the specified score range.
+//
+// https://redis.io/commands/zcount
+func (u *Upstash) ZCount(ctx context.Context, key string, min string, max string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
+		Path: []string{"zcount", key, min, max},
+	}))
+}
+
+// Increments the score of member in the sorted set stored at key by
+// increment. If member does not exist in the sorted set, it is added with
+// increment as its score.
+//
+// Returns the new score of member.
+//
+// https://redis.io/commands/zincrby
+func (u *Upstash) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return floatResult(u.client.Write(ctx, client.Request{
+		Body: []string{"zincrby", key, formatScore(increment), member},
+	}))
+}
+
+func zstoreArgs(command string, destination string, keys []string, options ZStoreOptions) []string {
+	args := append([]string{command, destination, fmt.Sprintf("%d", len(keys))}, keys...)
+	if len(options.Weights) > 0 {
+		args = append(args, "weights")
+		for _, weight := range options.Weights {
+			args = append(args, formatScore(weight))
+		}
+	}
+	if options.Aggregate != "" {
+		args = append(args, "aggregate", options.Aggregate)
+	}
+	return args
+}
+
+// Computes the intersection of the sorted sets given by keys, and stores the
+// result in destination. By default, the resulting score of an element is
+// the sum of its scores in the sorted sets where it exists.
+//
+// Returns the number of elements in the resulting sorted set at destination.
+//
+// https://redis.io/commands/zinterstore
+func (u *Upstash) ZInterStore(ctx context.Context, destination string, keys []string, options ZStoreOptions) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: zstoreArgs("zinterstore", destination, keys, options),
+	}))
+}
+
+func (u *Upstash) zpop(ctx context.Context, command string, key string, count int) ([]Z, error) {
+	return zSliceResult(u.client.Write(ctx, client.Request{
+		Body: []string{command, key, fmt.Sprintf("%d", count)},
+	}))
+}
+
+// Removes and returns up to count members with the highest scores in the
+// sorted set stored at key.
+//
+// Returns the popped elements and scores, highest score first.
+//
+// https://redis.io/commands/zpopmax
+func (u *Upstash) ZPopMax(ctx context.Context, key string, count int) ([]Z, error) {
+	return u.zpop(ctx, "zpopmax", key, count)
+}
+
+// Removes and returns up to count members with the lowest scores in the
+// sorted set stored at key.
+//
+// Returns the popped elements and scores, lowest score first.
+//
+// https://redis.io/commands/zpopmin
+func (u *Upstash) ZPopMin(ctx context.Context, key string, count int) ([]Z, error) {
+	return u.zpop(ctx, "zpopmin", key, count)
+}
+
+func zrangeArgs(key string, start string, stop string, options ZRangeOptions) []string {
+	args := []string{"zrange", key, start, stop}
+	if options.ByScore {
+		args = append(args, "byscore")
+	} else if options.ByLex {
+		args = append(args, "bylex")
+	}
+	if options.Rev {
+		args = append(args, "rev")
+	}
+	if options.Offset != 0 || options.Count != 0 {
+		count := options.Count
+		if count == 0 {
+			count = -1
+		}
+		args = append(args, "limit", fmt.Sprintf("%d", options.Offset), fmt.Sprintf("%d", count))
+	}
+	return args
+}
+
+// Returns the specified range of elements in the sorted set stored at key.
+//
+// By default start and stop are zero-based indexes, negative values count
+// from the end of the sorted set. Set ByScore or ByLex in the options to
+// select elements by score or lexicographical range instead.
+//
+// Returns the members in the specified range.
+//
+// https://redis.io/commands/zrange
+func (u *Upstash) ZRange(ctx context.Context, key string, start string, stop string, options ZRangeOptions) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: zrangeArgs(key, start, stop, options),
+	}))
+}
+
+// Same as ZRange but returns the members together with their scores.
+// WITHSCORES can not be combined with ByLex.
+//
+// https://redis.io/commands/zrange
+func (u *Upstash) ZRangeWithScores(ctx context.Context, key string, start string, stop string, options ZRangeOptions) ([]Z, error) {
+	return zSliceResult(u.client.Read(ctx, client.Request{
+		Path: append(zrangeArgs(key, start, stop, options), "withscores"),
+	}))
+}
+
+func (u *Upstash) zrank(ctx context.Context, command string, key string, member string) (int64, error) {
+	return int64Result(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{command, key, member},
+	})))
+}
+
+// Returns the rank of member in the sorted set stored at key, with the
+// scores ordered from low to high. The rank is 0-based, which means that the
+// member with the lowest score has rank 0.
+//
+// Returns the rank of member, or ErrNil when member or key does not exist.
+//
+// https://redis.io/commands/zrank
+func (u *Upstash) ZRank(ctx context.Context, key string, member string) (int64, error) {
+	return u.zrank(ctx, "zrank", key, member)
+}
+
+// Removes the specified members from the sorted set stored at key. Non
+// existing members are ignored.
+//
+// Returns the number of members removed from the sorted set.
+//
+// https://redis.io/commands/zrem
+func (u *Upstash) ZRem(ctx context.Context, key string, members []string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: append([]string{"zrem", key}, members...),
+	}))
+}
+
+// Removes all elements in the sorted set stored at key with rank between
+// start and stop. Both are 0-based indexes and can be negative to count from
+// the element with the highest score.
+//
+// Returns the number of elements removed.
+//
+// https://redis.io/commands/zremrangebyrank
+func (u *Upstash) ZRemRangeByRank(ctx context.Context, key string, start int, stop int) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: []string{"zremrangebyrank", key, fmt.Sprintf("%d", start), fmt.Sprintf("%d", stop)},
+	}))
+}
+
+// Removes all elements in the sorted set stored at key with a score between
+// min and max, using the same syntax as ZCount.
+//
+// Returns the number of elements removed.
+//
+// https://redis.io/commands/zremrangebyscore
+func (u *Upstash) ZRemRangeByScore(ctx context.Context, key string, min string, max string) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: []string{"zremrangebyscore", key, min, max},
+	}))
+}
+
+// Returns the rank of member in the sorted set stored at key, with the
+// scores ordered from high to low. The rank is 0-based, which means that the
+// member with the highest score has rank 0.
+//
+// Returns the rank of member, or ErrNil when member or key does not exist.
+//
+// https://redis.io/commands/zrevrank
+func (u *Upstash) ZRevRank(ctx context.Context, key string, member string) (int64, error) {
+	return u.zrank(ctx, "zrevrank", key, member)
+}
+
+// Iterates members of the sorted set stored at key, starting at cursor.
+// Start with a cursor of 0 and call ZSCAN with the returned cursor until it
+// returns 0 again.
+//
+// Returns the cursor for the next call and a page of members with their
+// scores.
+//
+// https://redis.io/commands/zscan
+func (u *Upstash) ZScan(ctx context.Context, key string, cursor uint64, options ScanOptions) (uint64, []Z, error) {
+	next, values, err := scanResult(u.client.Read(ctx, client.Request{
+		Path: append([]string{"zscan", key, fmt.Sprintf("%d", cursor)}, scanArgs(options)...),
+	}))
+	if err != nil {
+		return 0, nil, err
+	}
+	pairs, err := toKVs(values)
+	if err != nil {
+		return 0, nil, err
+	}
+	zs, err := toZs(pairs)
+	return next, zs, err
+}
+
+// Returns the score of member in the sorted set at key.
+//
+// Returns the score of member, or ErrNil when member or key does not exist.
+//
+// https://redis.io/commands/zscore
+func (u *Upstash) ZScore(ctx context.Context, key string, member string) (float64, error) {
+	return floatResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"zscore", key, member},
+	})))
+}
+
+// Computes the union of the sorted sets given by keys, and stores the result
+// in destination. By default, the resulting score of an element is the sum
+// of its scores in the sorted sets where it exists.
+//
+// Returns the number of elements in the resulting sorted set at destination.
+//
+// https://redis.io/commands/zunionstore
+func (u *Upstash) ZUnionStore(ctx context.Context, destination string, keys []string, options ZStoreOptions) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
+		Body: zstoreArgs("zunionstore", destination, keys, options),
+	}))
+}
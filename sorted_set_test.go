@@ -0,0 +1,266 @@
+package upstash_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZAdd(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	added, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2.5, Member: "b"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), added)
+
+	got, err := u.ZRangeWithScores(ctx, key, "0", "-1", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2.5, Member: "b"}}, got)
+}
+
+func TestZAddWithOptions(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 5, Member: "a"}})
+	require.NoError(t, err)
+
+	added, err := u.ZAddWithOptions(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 1, Member: "b"}}, upstash.ZAddOptions{NX: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), added)
+
+	changed, err := u.ZAddWithOptions(ctx, key, []upstash.Z{{Score: 3, Member: "a"}, {Score: 3, Member: "b"}}, upstash.ZAddOptions{GT: true, CH: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), changed)
+
+	added, err = u.ZAddWithOptions(ctx, key, []upstash.Z{{Score: 0, Member: "c"}}, upstash.ZAddOptions{XX: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), added)
+
+	got, err := u.ZRangeWithScores(ctx, key, "0", "-1", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 3, Member: "b"}, {Score: 5, Member: "a"}}, got)
+}
+
+func TestZAddIncr(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	score, err := u.ZAddIncr(ctx, key, upstash.Z{Score: 1.5, Member: "a"}, upstash.ZAddOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1.5, score)
+
+	score, err = u.ZAddIncr(ctx, key, upstash.Z{Score: 2, Member: "a"}, upstash.ZAddOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3.5, score)
+}
+
+func TestZIncrBy(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	score, err := u.ZIncrBy(ctx, key, 2, "a")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, score)
+
+	score, err = u.ZScore(ctx, key, "a")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, score)
+}
+
+func TestZRange(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}, {Score: 3, Member: "c"}, {Score: 4, Member: "d"}})
+	require.NoError(t, err)
+
+	got, err := u.ZRange(ctx, key, "1", "2", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, got)
+
+	got, err = u.ZRange(ctx, key, "0", "1", upstash.ZRangeOptions{Rev: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "c"}, got)
+
+	got, err = u.ZRange(ctx, key, "(1", "+inf", upstash.ZRangeOptions{ByScore: true, Offset: 1, Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, got)
+
+	got, err = u.ZRange(ctx, key, "3", "-inf", upstash.ZRangeOptions{ByScore: true, Rev: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b", "a"}, got)
+
+	scored, err := u.ZRangeWithScores(ctx, key, "-inf", "2", upstash.ZRangeOptions{ByScore: true})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}}, scored)
+}
+
+func TestZRangeByLex(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Member: "a"}, {Member: "b"}, {Member: "c"}, {Member: "d"}})
+	require.NoError(t, err)
+
+	got, err := u.ZRange(ctx, key, "[b", "(d", upstash.ZRangeOptions{ByLex: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, got)
+
+	got, err = u.ZRange(ctx, key, "+", "-", upstash.ZRangeOptions{ByLex: true, Rev: true, Count: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "c"}, got)
+}
+
+func TestZRank(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}, {Score: 3, Member: "c"}})
+	require.NoError(t, err)
+
+	rank, err := u.ZRank(ctx, key, "b")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rank)
+
+	rank, err = u.ZRevRank(ctx, key, "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rank)
+
+	rank, err = u.ZRank(ctx, key, "missing")
+	require.ErrorIs(t, err, upstash.ErrNil)
+}
+
+func TestZCardAndZCount(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}, {Score: math.Inf(1), Member: "c"}})
+	require.NoError(t, err)
+
+	cardinality, err := u.ZCard(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), cardinality)
+
+	count, err := u.ZCount(ctx, key, "(1", "+inf")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	score, err := u.ZScore(ctx, key, "c")
+	require.NoError(t, err)
+	require.True(t, math.IsInf(score, 1))
+}
+
+func TestZRem(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}, {Score: 3, Member: "c"}, {Score: 4, Member: "d"}, {Score: 5, Member: "e"}})
+	require.NoError(t, err)
+
+	removed, err := u.ZRem(ctx, key, []string{"a", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	removed, err = u.ZRemRangeByRank(ctx, key, -1, -1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	removed, err = u.ZRemRangeByScore(ctx, key, "2", "(4")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), removed)
+
+	got, err := u.ZRange(ctx, key, "0", "-1", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d"}, got)
+}
+
+func TestZPop(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}, {Score: 3, Member: "c"}})
+	require.NoError(t, err)
+
+	min, err := u.ZPopMin(ctx, key, 1)
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 1, Member: "a"}}, min)
+
+	max, err := u.ZPopMax(ctx, key, 5)
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 3, Member: "c"}, {Score: 2, Member: "b"}}, max)
+
+	empty, err := u.ZPopMin(ctx, key, 1)
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{}, empty)
+}
+
+func TestZUnionStore(t *testing.T) {
+	key1 := uuid.NewString()
+	key2 := uuid.NewString()
+	destination := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.ZAdd(ctx, key1, []upstash.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}})
+	require.NoError(t, err)
+	_, err = u.ZAdd(ctx, key2, []upstash.Z{{Score: 1, Member: "b"}, {Score: 3, Member: "c"}})
+	require.NoError(t, err)
+
+	n, err := u.ZUnionStore(ctx, destination, []string{key1, key2}, upstash.ZStoreOptions{Weights: []float64{2, 1}})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	got, err := u.ZRangeWithScores(ctx, destination, "0", "-1", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 2, Member: "a"}, {Score: 3, Member: "c"}, {Score: 5, Member: "b"}}, got)
+
+	n, err = u.ZInterStore(ctx, destination, []string{key1, key2}, upstash.ZStoreOptions{Aggregate: "MAX"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err = u.ZRangeWithScores(ctx, destination, "0", "-1", upstash.ZRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []upstash.Z{{Score: 2, Member: "b"}}, got)
+}
+
+func TestZScan(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	members := []upstash.Z{}
+	for i := 0; i < 20; i++ {
+		members = append(members, upstash.Z{Score: float64(i), Member: uuid.NewString()})
+	}
+	_, err := u.ZAdd(ctx, key, members)
+	require.NoError(t, err)
+
+	got := []upstash.Z{}
+	cursor := uint64(0)
+	for {
+		next, page, err := u.ZScan(ctx, key, cursor, upstash.ScanOptions{Count: 5})
+		require.NoError(t, err)
+		got = append(got, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	require.ElementsMatch(t, members, got)
+}
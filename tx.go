@@ -0,0 +1,91 @@
+package upstash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chronark/upstash-go/client"
+)
+
+// Returned by Tx.Exec when Upstash discarded the transaction, e.g. because
+// one of the commands was invalid. None of the commands were executed.
+var ErrTxAborted = errors.New("Transaction aborted")
+
+// Tx queues commands and executes them atomically in a single MULTI/EXEC
+// block when Exec is called, using the `/multi-exec` endpoint. Either all or
+// none of the commands are executed and no other client can run commands in
+// between.
+//
+// Tx has the same methods as Upstash and Pipeline. Queued commands return
+// zero values, their results are returned by Exec in the same order.
+//
+//	tx := u.TxPipeline()
+//	tx.DecrBy(ctx, "account:a", 10)
+//	tx.IncrBy(ctx, "account:b", 10)
+//	res, err := tx.Exec(ctx)
+//
+// A Tx is not safe for concurrent use.
+type Tx struct {
+	Pipeline
+}
+
+// TxResult is the result of an executed transaction.
+type TxResult struct {
+	// One Cmd per queued command, in order.
+	Cmds []*Cmd
+
+	// Set when the transaction was discarded and none of the commands were
+	// executed.
+	Aborted bool
+}
+
+// Returns the first error of any command in the transaction, if any. A
+// command failing at runtime, e.g. INCR on a key holding a list, does not
+// roll back the other commands.
+func (r *TxResult) Err() error {
+	for _, cmd := range r.Cmds {
+		if cmd.err != nil {
+			return cmd.err
+		}
+	}
+	return nil
+}
+
+// Creates a new transaction, see Tx.
+//
+// https://redis.io/commands/multi
+func (u *Upstash) TxPipeline() *Tx {
+	return &Tx{Pipeline: *u.Pipeline()}
+}
+
+// Queues commands in fn and executes them atomically, see Tx. Nothing is
+// sent when fn returns an error.
+func (u *Upstash) TxPipelined(ctx context.Context, fn func(tx *Tx) error) (*TxResult, error) {
+	tx := u.TxPipeline()
+	if err := fn(tx); err != nil {
+		tx.Discard()
+		return nil, err
+	}
+	return tx.Exec(ctx)
+}
+
+// Executes all queued commands atomically and clears the queue.
+//
+// Returns one Cmd per queued command. Errors of single commands are returned
+// by their Cmd.Err and TxResult.Err. If the transaction was aborted, the
+// result is marked as Aborted and an error wrapping ErrTxAborted is returned.
+//
+// https://redis.io/commands/exec
+func (tx *Tx) Exec(ctx context.Context) (*TxResult, error) {
+	cmds, err := tx.send(ctx, "multi-exec")
+	if err != nil {
+		// Redis rejected the transaction before executing any command
+		var redisErr *client.RedisError
+		if errors.As(err, &redisErr) {
+			return &TxResult{Cmds: cmds, Aborted: true}, fmt.Errorf("%w: %s", ErrTxAborted, err)
+		}
+		return nil, err
+	}
+	return &TxResult{Cmds: cmds}, nil
+}
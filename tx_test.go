@@ -0,0 +1,81 @@
+package upstash_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chronark/upstash-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx(t *testing.T) {
+	from := uuid.NewString()
+	to := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.MSet(ctx, []upstash.KV{{Key: from, Value: "100"}, {Key: to, Value: "0"}})
+	require.NoError(t, err)
+
+	tx := u.TxPipeline()
+	_, _ = tx.DecrBy(ctx, from, 10)
+	_, _ = tx.IncrBy(ctx, to, 10)
+	require.Equal(t, 2, tx.Len())
+
+	res, err := tx.Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+	require.False(t, res.Aborted)
+	require.Len(t, res.Cmds, 2)
+
+	n, err := res.Cmds[0].Int()
+	require.NoError(t, err)
+	require.Equal(t, 90, n)
+
+	n, err = res.Cmds[1].Int()
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+}
+
+func TestTxWithCommandError(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	res, err := u.TxPipelined(ctx, func(tx *upstash.Tx) error {
+		_, _ = tx.LPush(ctx, key, []string{"a"})
+		_, _ = tx.Incr(ctx, key)
+		_, _ = tx.LLen(ctx, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.False(t, res.Aborted)
+	require.Error(t, res.Err())
+	require.NoError(t, res.Cmds[0].Err())
+	require.Error(t, res.Cmds[1].Err())
+
+	length, err := res.Cmds[2].Int()
+	require.NoError(t, err)
+	require.Equal(t, 1, length)
+}
+
+func TestTxAborted(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	tx := u.TxPipeline()
+	_ = tx.Set(ctx, key, "value")
+	_, _ = tx.Exists(ctx, []string{})
+
+	res, err := tx.Exec(ctx)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, upstash.ErrTxAborted))
+	require.True(t, res.Aborted)
+
+	exists, err := u.Exists(ctx, []string{key})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), exists)
+}
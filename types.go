@@ -39,3 +39,134 @@ type GetEXOptions struct {
 	//  Remove the time to live associated with the key.
 	PERSIST bool
 }
+
+// A string value that may not exist, e.g. a missing field in HMGET.
+type NullString struct {
+	Value string
+
+	// Valid is false when the value does not exist.
+	Valid bool
+}
+
+// The SCAN family of commands supports a set of options that modify its
+// behavior:
+type ScanOptions struct {
+
+	// Only return elements matching the glob-style pattern.
+	Match string
+
+	// A hint for the amount of work done per call, defaults to 10.
+	Count int
+
+	// Only return keys holding a value of this type, e.g. "string" or
+	// "zset". Only supported by SCAN.
+	Type string
+}
+
+// The side of a list elements are moved from or to by LMOVE.
+type ListDirection string
+
+const (
+	Left  ListDirection = "left"
+	Right ListDirection = "right"
+)
+
+// Where LINSERT places the element relative to the pivot.
+type InsertPosition string
+
+const (
+	Before InsertPosition = "before"
+	After  InsertPosition = "after"
+)
+
+// The LPOS command supports a set of options that modify its behavior:
+type LPosOptions struct {
+
+	// Skip the first matches, e.g. 2 returns the second match. A negative
+	// rank searches from the tail of the list.
+	Rank int
+
+	// Only compare the element with at most this many items of the list.
+	MaxLen int
+}
+
+// A member of a sorted set together with its score.
+type Z struct {
+	Score  float64
+	Member string
+}
+
+// The ZADD command supports a set of options that modify its behavior:
+type ZAddOptions struct {
+
+	// Only update elements that already exist. Don't add new elements.
+	XX bool
+
+	// Only add new elements. Don't update already existing elements.
+	NX bool
+
+	// Only update existing elements if the new score is greater than the
+	// current score. This flag doesn't prevent adding new elements.
+	GT bool
+
+	// Only update existing elements if the new score is less than the
+	// current score. This flag doesn't prevent adding new elements.
+	LT bool
+
+	// Return the number of changed elements instead of the number of new
+	// elements added. Changed elements are new elements added and elements
+	// already existing for which the score was updated.
+	CH bool
+}
+
+// The ZRANGE command supports a set of options that modify its behavior:
+type ZRangeOptions struct {
+
+	// Interpret start and stop as scores, e.g. "-inf", "(1.5" or "+inf".
+	ByScore bool
+
+	// Interpret start and stop as lexicographical ranges, e.g. "-", "[a" or
+	// "(b".
+	ByLex bool
+
+	// Reverse the ordering, elements are ordered from the highest to the
+	// lowest score. With ByScore or ByLex start must be the upper bound.
+	Rev bool
+
+	// Skip this many elements, only valid together with ByScore or ByLex.
+	Offset int
+
+	// Return at most this many elements, only valid together with ByScore
+	// or ByLex. Zero means no limit.
+	Count int
+}
+
+// The ZUNIONSTORE and ZINTERSTORE commands support a set of options that
+// modify their behavior:
+type ZStoreOptions struct {
+
+	// A multiplication factor for the scores of each input sorted set.
+	Weights []float64
+
+	// How scores of elements existing in multiple sets are combined: SUM,
+	// MIN or MAX. Defaults to SUM.
+	Aggregate string
+}
+
+// The EXPIRE family of commands supports a set of options that modify its
+// behavior. NX, GT and LT exclude each other and NX can not be combined
+// with XX, an error is returned otherwise. XX may be combined with GT or LT.
+type ExpireOptions struct {
+
+	// Set expiry only when the key has no expiry.
+	NX bool
+
+	// Set expiry only when the key has an existing expiry.
+	XX bool
+
+	// Set expiry only when the new expiry is greater than current one.
+	GT bool
+
+	// Set expiry only when the new expiry is less than current one.
+	LT bool
+}
@@ -1,15 +1,24 @@
 package upstash
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/chronark/upstash-go/client"
 )
 
 type Upstash struct {
 	client client.Client
+
+	// Set when commands are queued by a Pipeline or Tx, their results are
+	// not known until the commands are executed.
+	queued bool
 }
 
 type Options struct {
@@ -25,8 +34,66 @@ type Options struct {
 	// Requests to the Upstash API must provide an API token.
 	Token string
 
-	// Read requests will try to read from edge first
+	// Read requests will try to read from edge first and fall back to the
+	// primary url when the edge request fails. Use client.ForcePrimary to
+	// read from the primary for a single call.
 	ReadFromEdge bool
+
+	// Guarantee that reads see all writes made before by this instance, even
+	// when reading from edge. Upstash returns a sync token with every
+	// response, which is sent with the following requests.
+	ReadYourWrites bool
+
+	// Retry requests that failed because of network errors or a retryable
+	// status code. Retries are disabled by default, see
+	// client.DefaultRetryPolicy for a sensible configuration.
+	Retry client.RetryPolicy
+
+	// Send requests with this client, e.g. to share a connection pool with
+	// the rest of your application. Can not be combined with Timeout,
+	// Transport or TLSConfig, configure your client instead.
+	HTTPClient client.HTTPClient
+
+	// Time limit for a single request, including connecting, redirects and
+	// reading the response body. No limit if 0.
+	Timeout time.Duration
+
+	// The transport used to perform requests, e.g. to use a proxy or to set
+	// a dial timeout. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// TLS configuration for the default transport, or for Transport if it
+	// is an *http.Transport.
+	TLSConfig *tls.Config
+}
+
+// Build the http client described by the options.
+func newHTTPClient(options Options) (client.HTTPClient, error) {
+	if options.HTTPClient != nil {
+		if options.Timeout != 0 || options.Transport != nil || options.TLSConfig != nil {
+			return nil, fmt.Errorf("HTTPClient can not be combined with Timeout, Transport or TLSConfig")
+		}
+		return options.HTTPClient, nil
+	}
+
+	transport := options.Transport
+	if options.TLSConfig != nil {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		t, ok := transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("TLSConfig requires Transport to be an *http.Transport, got %T", transport)
+		}
+		t = t.Clone()
+		t.TLSClientConfig = options.TLSConfig
+		transport = t
+	}
+
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+	}, nil
 }
 
 func New(options Options) (Upstash, error) {
@@ -42,11 +109,31 @@ func New(options Options) (Upstash, error) {
 		options.Token = os.Getenv("UPSTASH_REDIS_REST_TOKEN")
 	}
 
+	httpClient, err := newHTTPClient(options)
+	if err != nil {
+		return Upstash{}, err
+	}
+
 	return Upstash{
-		client: client.New(options.Url, options.EdgeUrl, options.Token),
+		client: client.New(
+			options.Url,
+			options.EdgeUrl,
+			options.Token,
+			client.WithHTTPClient(httpClient),
+			client.WithRetryPolicy(options.Retry),
+			client.WithReadFromEdge(options.ReadFromEdge),
+			client.WithReadYourWrites(options.ReadYourWrites),
+		),
 	}, nil
 }
 
+// Creates an instance that sends all commands with c, e.g. a
+// clienttest.Mock in unit tests or a client.Client wrapping the default
+// client.
+func NewWithClient(c client.Client) Upstash {
+	return Upstash{client: c}
+}
+
 type Response struct {
 	Result string `json:"result"`
 	Error  string `json:"error"`
@@ -69,14 +156,10 @@ type Response struct {
 
 // Return value
 // Array reply: list of keys matching pattern.
-func (u *Upstash) Keys(pattern string) ([]string, error) {
-	res, err := u.client.Read(client.Request{
-		Body: []string{"keys", pattern},
-	})
-	if res == nil {
-		return []string{}, nil
-	}
-	return res.([]string), err
+func (u *Upstash) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return stringSliceResult(u.client.Read(ctx, client.Request{
+		Path: []string{"keys", pattern},
+	}))
 }
 
 // If key already exists and is a string, this command appends the value at
@@ -86,12 +169,11 @@ func (u *Upstash) Keys(pattern string) ([]string, error) {
 // Return the length of the string after the append operation.
 //
 // https://redis.io/commands/append
-func (u *Upstash) Append(key string, value string) (int, error) {
+func (u *Upstash) Append(ctx context.Context, key string, value string) (int64, error) {
 
-	res, err := u.client.Write(client.Request{
+	return int64Result(u.client.Write(ctx, client.Request{
 		Body: []string{"append", key, value},
-	})
-	return int(res.(float64)), err
+	}))
 }
 
 // Decrements the number stored at key by one. If the key does not exist, it is
@@ -104,12 +186,11 @@ func (u *Upstash) Append(key string, value string) (int, error) {
 // Returns  the value of key after the decrement
 //
 // https://redis.io/commands/decr
-func (u *Upstash) Decr(key string) (int, error) {
+func (u *Upstash) Decr(ctx context.Context, key string) (int64, error) {
 
-	res, err := u.client.Write(client.Request{
+	return int64Result(u.client.Write(ctx, client.Request{
 		Body: []string{"decr", key},
-	})
-	return int(res.(float64)), err
+	}))
 }
 
 // Decrements the number stored at key by decrement. If the key does not
@@ -123,34 +204,88 @@ func (u *Upstash) Decr(key string) (int, error) {
 // Returns the value of key after the decrement
 //
 // https://redis.io/commands/decrby
-func (u *Upstash) DecrBy(key string, decrement int) (int, error) {
+func (u *Upstash) DecrBy(ctx context.Context, key string, decrement int64) (int64, error) {
 
-	res, err := u.client.Write(client.Request{
+	return int64Result(u.client.Write(ctx, client.Request{
 		Body: []string{"decrby", key, fmt.Sprintf("%d", decrement)},
-	})
-	return int(res.(float64)), err
+	}))
 }
 
-// Get the value of key. If the key does not exist the special value nil is
-// returned. An error is returned if the value stored at key is not a
-// string, because GET only handles string values.
+// Get the value of key. If the key does not exist ErrNil is returned. An
+// error is returned if the value stored at key is not a string, because GET
+// only handles string values.
 //
-// Returns the value of key, or empty string when key does not exist.
+// Returns the value of key, or ErrNil when key does not exist.
 //
 // https://redis.io/commands/get
-func (u *Upstash) Get(key string) (string, error) {
+func (u *Upstash) Get(ctx context.Context, key string) (string, error) {
 
-	res, err := u.client.Read(client.Request{
+	return stringResult(u.nilResult(u.client.Read(ctx, client.Request{
 		Path: []string{"get", key},
-	})
-	if err != nil {
-		return "", err
+	})))
+}
+
+// Same as Get but returns the value as bytes, for binary values stored with
+// SetBytes.
+//
+// https://redis.io/commands/get
+func (u *Upstash) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	return bytesResult(u.nilResult(u.client.Read(ctx, client.Request{
+		Path: []string{"get", key},
+	})))
+}
+
+// Get the value of key and delete the key. This command is similar to GET,
+// except for the fact that it also deletes the key on success (if and only
+// if the key's value type is a string).
+//
+// Returns the value of key, or ErrNil when key does not exist.
+//
+// https://redis.io/commands/getdel
+func (u *Upstash) GetDel(ctx context.Context, key string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: []string{"getdel", key},
+	})))
+}
+
+// Get the value of key and optionally set its expiration. GETEX is similar
+// to GET, but is a write command with additional options.
+//
+// Only one of the options may be set, an error is returned otherwise.
+//
+// Returns the value of key, or ErrNil when key does not exist.
+//
+// https://redis.io/commands/getex
+func (u *Upstash) GetEX(ctx context.Context, key string, options GetEXOptions) (string, error) {
+	body := []string{"getex", key}
+	set := []string{}
+	if options.EX != 0 {
+		body = append(body, "ex", fmt.Sprintf("%d", options.EX))
+		set = append(set, "EX")
+	}
+	if options.PX != 0 {
+		body = append(body, "px", fmt.Sprintf("%d", options.PX))
+		set = append(set, "PX")
+	}
+	if options.EXAT != 0 {
+		body = append(body, "exat", fmt.Sprintf("%d", options.EXAT))
+		set = append(set, "EXAT")
+	}
+	if options.PXAT != 0 {
+		body = append(body, "pxat", fmt.Sprintf("%d", options.PXAT))
+		set = append(set, "PXAT")
 	}
-	if res == nil {
-		return "", nil
+	if options.PERSIST {
+		body = append(body, "persist")
+		set = append(set, "PERSIST")
+	}
+	if len(set) > 1 {
+		return "", fmt.Errorf("Only one of EX, PX, EXAT, PXAT and PERSIST may be set, got %s", strings.Join(set, ", "))
 	}
 
-	return res.(string), nil
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
+		Body: body,
+	})))
 }
 
 // Returns the substring of the string value stored at key, determined by
@@ -165,16 +300,11 @@ func (u *Upstash) Get(key string) (string, error) {
 // not exist
 //
 // https://redis.io/commands/getrange
-func (u *Upstash) GetRange(key string, start int, end int) (string, error) {
+func (u *Upstash) GetRange(ctx context.Context, key string, start int, end int) (string, error) {
 
-	res, err := u.client.Read(client.Request{
+	return stringResult(u.client.Read(ctx, client.Request{
 		Path: []string{"getrange", key, fmt.Sprintf("%d", start), fmt.Sprintf("%d", end)},
-	})
-	if err != nil {
-		return "", err
-	}
-
-	return res.(string), nil
+	}))
 }
 
 // Atomically sets key to value and returns the old value stored at key.
@@ -182,18 +312,13 @@ func (u *Upstash) GetRange(key string, start int, end int) (string, error) {
 // previous time to live associated with the key is discarded on successful
 // SET operation.
 //
-// Returns the old value stored at key, or empty string when key did not exist.
+// Returns the old value stored at key, or ErrNil when key did not exist.
 //
 // https://redis.io/commands/getset
-func (u *Upstash) GetSet(key string, value string) (string, error) {
-	res, err := u.client.Write(client.Request{
+func (u *Upstash) GetSet(ctx context.Context, key string, value string) (string, error) {
+	return stringResult(u.nilResult(u.client.Write(ctx, client.Request{
 		Body: []string{"getset", key, value},
-	})
-	if err != nil {
-		return "", err
-	}
-
-	return res.(string), nil
+	})))
 }
 
 // Increments the number stored at key by one. If the key does not exist,
@@ -213,12 +338,11 @@ func (u *Upstash) GetSet(key string, value string) (string, error) {
 // Returns the value of key after the increment
 //
 // https://redis.io/commands/incr
-func (u *Upstash) Incr(key string) (int, error) {
+func (u *Upstash) Incr(ctx context.Context, key string) (int64, error) {
 
-	res, err := u.client.Write(client.Request{
+	return int64Result(u.client.Write(ctx, client.Request{
 		Body: []string{"incr", key},
-	})
-	return int(res.(float64)), err
+	}))
 }
 
 // Increments the number stored at key by increment. If the key does not
@@ -232,11 +356,10 @@ func (u *Upstash) Incr(key string) (int, error) {
 // Returns the value of key after the increment
 //
 // https://redis.io/commands/incrby
-func (u *Upstash) IncrBy(key string, increment int) (int, error) {
-	res, err := u.client.Write(client.Request{
+func (u *Upstash) IncrBy(ctx context.Context, key string, increment int64) (int64, error) {
+	return int64Result(u.client.Write(ctx, client.Request{
 		Body: []string{"incrby", key, fmt.Sprintf("%d", increment)},
-	})
-	return int(res.(float64)), err
+	}))
 
 }
 
@@ -266,39 +389,24 @@ func (u *Upstash) IncrBy(key string, increment int) (int, error) {
 // Returns the value of key after the increment.
 //
 //https://redis.io/commands/incrbyfloat
-func (u *Upstash) IncrByFloat(key string, increment float64) (float64, error) {
-	res, err := u.client.Write(client.Request{
-		Body: []string{"incrbyfloat", key, fmt.Sprintf("%f", increment)},
-	})
-	if err != nil {
-		return 0, err
-	}
-	f, err := strconv.ParseFloat(res.(string), 10)
-	if err != nil {
-		return 0, err
-	}
-	return f, nil
+func (u *Upstash) IncrByFloat(ctx context.Context, key string, increment float64) (float64, error) {
+	return floatResult(u.client.Write(ctx, client.Request{
+		Body: []string{"incrbyfloat", key, strconv.FormatFloat(increment, 'f', -1, 64)},
+	}))
 
 }
 
 // Returns the values of all specified keys. For every key that does not
-// hold a string value or does not exist, the special value nil is returned.
+// hold a string value or does not exist, an invalid NullString is returned.
 // Because of this, the operation never fails.
 //
 // Returns a list of values at the specified keys.
 //
 // https://redis.io/commands/mget
-func (u *Upstash) MGet(keys []string) ([]string, error) {
-	res, err := u.client.Read(client.Request{
+func (u *Upstash) MGet(ctx context.Context, keys []string) ([]NullString, error) {
+	return nullStringSliceResult(u.client.Read(ctx, client.Request{
 		Path: append([]string{"mget"}, keys...),
-	})
-
-	values := make([]string, len(keys))
-	for i, value := range res.([]interface{}) {
-		values[i] = fmt.Sprint(value)
-	}
-
-	return values, err
+	}))
 }
 
 // Sets the given keys to their respective values. MSET replaces existing
@@ -311,13 +419,13 @@ func (u *Upstash) MGet(keys []string) ([]string, error) {
 //Returns nil, MSET can't fail.
 //
 // https://redis.io/commands/mset
-func (u *Upstash) MSet(kvPairs []KV) error {
+func (u *Upstash) MSet(ctx context.Context, kvPairs []KV) error {
 	body := []string{"mset"}
 	for _, kv := range kvPairs {
 		body = append(body, kv.Key, kv.Value)
 	}
 
-	_, err := u.client.Write(client.Request{
+	_, err := u.client.Write(ctx, client.Request{
 		Body: body,
 	})
 	return err
@@ -339,25 +447,21 @@ func (u *Upstash) MSet(kvPairs []KV) error {
 // 0 if no key was set (at least one key already existed
 //
 // https://redis.io/commands/msetnx
-func (u *Upstash) MSetNX(kvPairs []KV) (int, error) {
+func (u *Upstash) MSetNX(ctx context.Context, kvPairs []KV) (int, error) {
 	body := []string{"msetnx"}
 	for _, kv := range kvPairs {
 		body = append(body, kv.Key, kv.Value)
 	}
 
-	res, err := u.client.Write(client.Request{
+	return intResult(u.client.Write(ctx, client.Request{
 		Body: body,
-	})
-	if res == nil {
-		return 0, nil
-	}
-	return int(res.(float64)), err
+	}))
 }
 
 // PSETEX works exactly like SETEX with the sole difference that the expire
 // time is specified in milliseconds instead of seconds.
-func (u *Upstash) PSetEX(key string, milliseconds int, value string) error {
-	_, err := u.client.Write(client.Request{
+func (u *Upstash) PSetEX(ctx context.Context, key string, milliseconds int, value string) error {
+	_, err := u.client.Write(ctx, client.Request{
 		Body: []string{"psetex", key, fmt.Sprintf("%d", milliseconds), value},
 	})
 	return err
@@ -368,17 +472,32 @@ func (u *Upstash) PSetEX(key string, milliseconds int, value string) error {
 // with the key is discarded on successful SET operation.
 //
 // https://redis.io/commands/set
-func (u *Upstash) Set(key string, value string) error {
-	_, err := u.client.Write(client.Request{
+func (u *Upstash) Set(ctx context.Context, key string, value string) error {
+	_, err := u.client.Write(ctx, client.Request{
 		Body: []string{"set", key, value},
 	})
 	return err
 }
 
+// Same as Set but for binary values. The value is sent as raw request body
+// instead of JSON, so it may contain any bytes.
+//
+// https://redis.io/commands/set
+func (u *Upstash) SetBytes(ctx context.Context, key string, value []byte) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Path: []string{"set", key},
+		Body: value,
+	})
+	return err
+}
+
 // Same as Set but with additional options
 //
+// Returns ErrNil when NX or XX is set and the value was not set, because
+// the condition was not met.
+//
 // https://redis.io/commands/set
-func (u *Upstash) SetWithOptions(key string, value string, options SetOptions) error {
+func (u *Upstash) SetWithOptions(ctx context.Context, key string, value string, options SetOptions) error {
 	body := []string{"set", key, value}
 	if options.EX != 0 {
 		body = append(body, "ex", fmt.Sprintf("%d", options.EX))
@@ -392,14 +511,15 @@ func (u *Upstash) SetWithOptions(key string, value string, options SetOptions) e
 		body = append(body, "xx")
 	}
 
-	_, err := u.client.Write(client.Request{
+	res, err := u.client.Write(ctx, client.Request{
 		Body: body,
 	})
 	if err != nil {
 
 		return fmt.Errorf("Error %s: %w", body, err)
 	}
-	return nil
+	_, err = u.nilResult(res, nil)
+	return err
 }
 
 // Set key to hold the string value and set key to timeout after a given
@@ -416,15 +536,26 @@ func (u *Upstash) SetWithOptions(key string, value string, options SetOptions) e
 // An error is returned when seconds is invalid.
 //
 // https://redis.io/commands/setex
-func (u *Upstash) SetEX(key string, seconds int, value string) error {
+func (u *Upstash) SetEX(ctx context.Context, key string, seconds int, value string) error {
 
-	_, err := u.client.Write(client.Request{
+	_, err := u.client.Write(ctx, client.Request{
 		Body: []string{"setex", key, fmt.Sprintf("%d", seconds), value},
 	})
 	return err
 
 }
 
+// Same as SetEX but for binary values, see SetBytes.
+//
+// https://redis.io/commands/setex
+func (u *Upstash) SetEXBytes(ctx context.Context, key string, seconds int, value []byte) error {
+	_, err := u.client.Write(ctx, client.Request{
+		Path: []string{"setex", key, fmt.Sprintf("%d", seconds)},
+		Body: value,
+	})
+	return err
+}
+
 // Set key to hold string value if key does not exist. In that case, it is
 // equal to SET. When key already holds a value, no operation is performed.
 // SETNX is short for "SET if Not eXists".
@@ -434,12 +565,11 @@ func (u *Upstash) SetEX(key string, seconds int, value string) error {
 // - 0 if the key was not set
 //
 // https://redis.io/commands/setnx
-func (u *Upstash) SetNX(key string, value string) (int, error) {
+func (u *Upstash) SetNX(ctx context.Context, key string, value string) (int, error) {
 
-	res, err := u.client.Write(client.Request{
+	return intResult(u.client.Write(ctx, client.Request{
 		Body: []string{"setnx", key, value},
-	})
-	return int(res.(float64)), err
+	}))
 
 }
 
@@ -467,9 +597,9 @@ func (u *Upstash) SetNX(key string, value string) (int, error) {
 // Returns the length of the string after it was modified by the command.
 //
 // https://redis.io/commands/setrange
-func (u *Upstash) SetRange(key string, offset int, value string) error {
+func (u *Upstash) SetRange(ctx context.Context, key string, offset int, value string) error {
 
-	_, err := u.client.Write(client.Request{
+	_, err := u.client.Write(ctx, client.Request{
 		Body: []string{"setrange", key, fmt.Sprintf("%d", offset), value},
 	})
 	return err
@@ -482,18 +612,16 @@ func (u *Upstash) SetRange(key string, offset int, value string) error {
 // Returns the length of the string at key, or 0 when key does not exist.
 //
 // https://redis.io/commands/strlen
-func (u *Upstash) StrLen(key string) (int, error) {
-	res, err := u.client.Read(client.Request{
+func (u *Upstash) StrLen(ctx context.Context, key string) (int64, error) {
+	return int64Result(u.client.Read(ctx, client.Request{
 		Path: []string{"strlen", key},
-	})
-
-	return int(res.(float64)), err
+	}))
 }
 
 // Delete all the keys of all the existing databases, not just the currently
 // selected one.
-func (u *Upstash) FlushAll() error {
-	_, err := u.client.Write(client.Request{
+func (u *Upstash) FlushAll(ctx context.Context) error {
+	_, err := u.client.Write(ctx, client.Request{
 		Body: []string{"flushall"},
 	})
 	return err
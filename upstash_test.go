@@ -1,12 +1,18 @@
 package upstash_test
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"fmt"
 
 	"github.com/chronark/upstash-go"
+	"github.com/chronark/upstash-go/client"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -15,11 +21,12 @@ func TestKeys(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	keys, err := u.Keys(key)
+	keys, err := u.Keys(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, []string{key}, keys)
 
@@ -30,15 +37,16 @@ func TestAppend(t *testing.T) {
 	value := uuid.NewString()
 	addition := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	length, err := u.Append(key, addition)
+	length, err := u.Append(ctx, key, addition)
 	require.NoError(t, err)
-	require.Equal(t, 72, length)
+	require.Equal(t, int64(72), length)
 
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, fmt.Sprintf("%s%s", value, addition), got)
 }
@@ -47,65 +55,135 @@ func TestDecr(t *testing.T) {
 	key := uuid.NewString()
 	value := "1"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	after, err := u.Decr(key)
+	after, err := u.Decr(ctx, key)
 	require.NoError(t, err)
 
-	require.Equal(t, 0, after)
+	require.Equal(t, int64(0), after)
 }
 
 func TestDecrBy(t *testing.T) {
 	key := uuid.NewString()
 	value := "5"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	after, err := u.DecrBy(key, 4)
+	after, err := u.DecrBy(ctx, key, 4)
 	require.NoError(t, err)
 
-	require.Equal(t, 1, after)
+	require.Equal(t, int64(1), after)
 }
 
 func TestGet(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 }
 
 func TestGetWithEmptyKey(t *testing.T) {
 	u, _ := upstash.New(upstash.Options{})
-	_, err := u.Get("")
+	ctx := context.Background()
+	_, err := u.Get(ctx, "")
 	require.Error(t, err)
 }
 
 func TestGetWithNonExistentKey(t *testing.T) {
 	u, _ := upstash.New(upstash.Options{})
-	got, err := u.Get(uuid.NewString())
-	require.NoError(t, err)
+	ctx := context.Background()
+	got, err := u.Get(ctx, uuid.NewString())
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got)
 }
 
+func TestGetDel(t *testing.T) {
+	key := uuid.NewString()
+	value := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, value)
+	require.NoError(t, err)
+
+	got, err := u.GetDel(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", got2)
+}
+
+func TestGetEX(t *testing.T) {
+	key := uuid.NewString()
+	value := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, value)
+	require.NoError(t, err)
+
+	got, err := u.GetEX(ctx, key, upstash.GetEXOptions{EX: 1})
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	time.Sleep(2 * time.Second)
+
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, "", got2)
+}
+
+func TestGetEX_PERSIST(t *testing.T) {
+	key := uuid.NewString()
+	value := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.SetEX(ctx, key, 10, value)
+	require.NoError(t, err)
+
+	got, err := u.GetEX(ctx, key, upstash.GetEXOptions{PERSIST: true})
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	ttl, err := u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, upstash.NoExpiration, ttl)
+}
+
+func TestGetEXWithMultipleOptions(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.GetEX(ctx, uuid.NewString(), upstash.GetEXOptions{EX: 1, PERSIST: true})
+	require.EqualError(t, err, "Only one of EX, PX, EXAT, PXAT and PERSIST may be set, got EX, PERSIST")
+}
+
 func TestGetRange(t *testing.T) {
 	key := uuid.NewString()
 	value := "abcde"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	got, err := u.GetRange(key, 1, 3)
+	got, err := u.GetRange(ctx, key, 1, 3)
 	require.NoError(t, err)
 	require.Equal(t, "bcd", got)
 }
@@ -115,15 +193,16 @@ func TestGetSet(t *testing.T) {
 	value := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	got, err := u.GetSet(key, value2)
+	got, err := u.GetSet(ctx, key, value2)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 
-	got2, err := u.Get(key)
+	got2, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value2, got2)
 
@@ -133,40 +212,60 @@ func TestIncr(t *testing.T) {
 	key := uuid.NewString()
 	value := "1"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	after, err := u.Incr(key)
+	after, err := u.Incr(ctx, key)
 	require.NoError(t, err)
-	require.Equal(t, 2, after)
+	require.Equal(t, int64(2), after)
 }
 
 func TestIncrBy(t *testing.T) {
 	key := uuid.NewString()
 	value := "5"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	after, err := u.IncrBy(key, 3)
+	after, err := u.IncrBy(ctx, key, 3)
 	require.NoError(t, err)
 
-	require.Equal(t, 8, after)
+	require.Equal(t, int64(8), after)
+}
+
+func TestIncrByLarge(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, "9007199254740992")
+	require.NoError(t, err)
+
+	after, err := u.IncrBy(ctx, key, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(9007199254740993), after)
 }
 func TestIncrByFloat(t *testing.T) {
 	key := uuid.NewString()
 	value := "5"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	after, err := u.IncrByFloat(key, 3.5)
+	after, err := u.IncrByFloat(ctx, key, 3.5)
 	require.NoError(t, err)
 
 	require.Equal(t, 8.5, after)
+
+	after, err = u.IncrByFloat(ctx, key, 0.0000001)
+	require.NoError(t, err)
+	require.InDelta(t, 8.5000001, after, 1e-9)
 }
 
 func TestMGet(t *testing.T) {
@@ -175,17 +274,18 @@ func TestMGet(t *testing.T) {
 	value1 := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key1, value1)
+	err := u.Set(ctx, key1, value1)
 	require.NoError(t, err)
 
-	err = u.Set(key2, value2)
+	err = u.Set(ctx, key2, value2)
 	require.NoError(t, err)
 
-	got, err := u.MGet([]string{key1, key2})
+	got, err := u.MGet(ctx, []string{key1, key2, uuid.NewString()})
 	require.NoError(t, err)
 
-	require.Equal(t, []string{value1, value2}, got)
+	require.Equal(t, []upstash.NullString{{Value: value1, Valid: true}, {Value: value2, Valid: true}, {}}, got)
 }
 
 func TestMSet(t *testing.T) {
@@ -194,16 +294,17 @@ func TestMSet(t *testing.T) {
 	value1 := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.MSet([]upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
+	err := u.MSet(ctx, []upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
 	require.NoError(t, err)
 
-	got1, err := u.Get(key1)
+	got1, err := u.Get(ctx, key1)
 	require.NoError(t, err)
 
 	require.Equal(t, value1, got1)
 
-	got2, err := u.Get(key2)
+	got2, err := u.Get(ctx, key2)
 	require.NoError(t, err)
 
 	require.Equal(t, value2, got2)
@@ -215,17 +316,18 @@ func TestMSetNX(t *testing.T) {
 	value1 := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	allSet, err := u.MSetNX([]upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
+	allSet, err := u.MSetNX(ctx, []upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
 	require.NoError(t, err)
 	require.Equal(t, 1, allSet)
 
-	got1, err := u.Get(key1)
+	got1, err := u.Get(ctx, key1)
 	require.NoError(t, err)
 
 	require.Equal(t, value1, got1)
 
-	got2, err := u.Get(key2)
+	got2, err := u.Get(ctx, key2)
 	require.NoError(t, err)
 
 	require.Equal(t, value2, got2)
@@ -237,11 +339,12 @@ func TestMSetNXWithExistingKeys(t *testing.T) {
 	value1 := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key1, value1)
+	err := u.Set(ctx, key1, value1)
 	require.NoError(t, err)
 
-	allSet, err := u.MSetNX([]upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
+	allSet, err := u.MSetNX(ctx, []upstash.KV{{Key: key1, Value: value1}, {Key: key2, Value: value2}})
 	require.NoError(t, err)
 	require.Equal(t, 0, allSet)
 
@@ -250,19 +353,20 @@ func TestPSetEX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.PSetEX(key, 1000, value)
+	err := u.PSetEX(ctx, key, 1000, value)
 	require.NoError(t, err)
 
-	got1, err := u.Get(key)
+	got1, err := u.Get(ctx, key)
 	require.NoError(t, err)
 
 	require.Equal(t, value, got1)
 
 	time.Sleep(2 * time.Second)
 
-	got2, err := u.Get(key)
-	require.NoError(t, err)
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got2)
 }
 
@@ -270,11 +374,12 @@ func TestSet(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 }
@@ -283,18 +388,19 @@ func TestSetWithOptions_EX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.SetWithOptions(key, value, upstash.SetOptions{
+	err := u.SetWithOptions(ctx, key, value, upstash.SetOptions{
 		EX: 2,
 	})
 	require.NoError(t, err)
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 
 	time.Sleep(5 * time.Second)
-	got2, err := u.Get(key)
-	require.NoError(t, err)
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got2)
 }
 
@@ -302,18 +408,19 @@ func TestSetWithOptions_PX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.SetWithOptions(key, value, upstash.SetOptions{
+	err := u.SetWithOptions(ctx, key, value, upstash.SetOptions{
 		PX: 2000,
 	})
 	require.NoError(t, err)
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 
 	time.Sleep(5 * time.Second)
-	got2, err := u.Get(key)
-	require.NoError(t, err)
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got2)
 }
 
@@ -321,17 +428,18 @@ func TestSetWithOptions_NX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.SetWithOptions(key, value, upstash.SetOptions{
+	err := u.SetWithOptions(ctx, key, value, upstash.SetOptions{
 		NX: true,
 	})
 	require.NoError(t, err)
 
-	err = u.SetWithOptions(key, uuid.NewString(), upstash.SetOptions{
+	err = u.SetWithOptions(ctx, key, uuid.NewString(), upstash.SetOptions{
 		NX: true,
 	})
-	require.NoError(t, err)
-	got, err := u.Get(key)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 
@@ -342,23 +450,24 @@ func TestSetWithOptions_XX(t *testing.T) {
 	value := uuid.NewString()
 	value2 := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.SetWithOptions(key, value, upstash.SetOptions{
+	err := u.SetWithOptions(ctx, key, value, upstash.SetOptions{
 		XX: true,
 	})
-	require.NoError(t, err)
-	got, err := u.Get(key)
-	require.NoError(t, err)
+	require.ErrorIs(t, err, upstash.ErrNil)
+	got, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got)
 
-	err = u.Set(key, value)
+	err = u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	err = u.SetWithOptions(key, value2, upstash.SetOptions{
+	err = u.SetWithOptions(ctx, key, value2, upstash.SetOptions{
 		XX: true,
 	})
 	require.NoError(t, err)
-	got2, err := u.Get(key)
+	got2, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value2, got2)
 
@@ -368,18 +477,19 @@ func TestSetEX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.SetEX(key, 1, value)
+	err := u.SetEX(ctx, key, 1, value)
 	require.NoError(t, err)
 
-	got1, err := u.Get(key)
+	got1, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, value, got1)
 
 	time.Sleep(2 * time.Second)
 
-	got2, err := u.Get(key)
-	require.NoError(t, err)
+	got2, err := u.Get(ctx, key)
+	require.ErrorIs(t, err, upstash.ErrNil)
 	require.Equal(t, "", got2)
 }
 
@@ -387,8 +497,9 @@ func TestSetNX(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	set, err := u.SetNX(key, value)
+	set, err := u.SetNX(ctx, key, value)
 	require.NoError(t, err)
 	require.Equal(t, 1, set)
 
@@ -398,11 +509,12 @@ func TestSetNXWithExistingKey(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	set, err := u.SetNX(key, value)
+	set, err := u.SetNX(ctx, key, value)
 	require.NoError(t, err)
 	require.Equal(t, 0, set)
 
@@ -413,14 +525,15 @@ func TestSetRange(t *testing.T) {
 	value := uuid.NewString()
 	overwrite := "HELLO"
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	err = u.SetRange(key, 4, overwrite)
+	err = u.SetRange(ctx, key, 4, overwrite)
 	require.NoError(t, err)
 
-	got, err := u.Get(key)
+	got, err := u.Get(ctx, key)
 	require.NoError(t, err)
 	require.Equal(t, fmt.Sprintf("%s%s%s", value[:4], overwrite, value[9:]), got)
 
@@ -430,13 +543,196 @@ func TestStrLen(t *testing.T) {
 	key := uuid.NewString()
 	value := uuid.NewString()
 	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
 
-	err := u.Set(key, value)
+	err := u.Set(ctx, key, value)
 	require.NoError(t, err)
 
-	res, err := u.StrLen(key)
+	res, err := u.StrLen(ctx, key)
 	require.NoError(t, err)
 
-	require.Equal(t, 36, res)
+	require.Equal(t, int64(36), res)
+
+}
+
+func TestCanceledContext(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := u.Set(ctx, key, "value")
+	require.True(t, errors.Is(err, context.Canceled))
 
+	_, err = u.Get(ctx, key)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	_, err = u.Pipelined(ctx, func(p *upstash.Pipeline) error {
+		return p.Set(ctx, key, "value")
+	})
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestContextDeadline(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+
+	_, err := u.Get(ctx, uuid.NewString())
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+type countingClient struct {
+	requests int
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultClient.Do(req)
+}
+
+func TestHTTPClient(t *testing.T) {
+	httpClient := &countingClient{}
+	u, err := upstash.New(upstash.Options{HTTPClient: httpClient})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = u.Set(ctx, uuid.NewString(), "value")
+	require.NoError(t, err)
+	require.Equal(t, 1, httpClient.requests)
+
+	_, err = upstash.New(upstash.Options{HTTPClient: httpClient, Timeout: time.Second})
+	require.Error(t, err)
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	u, err := upstash.New(upstash.Options{Url: srv.URL, Token: "token", Timeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = u.Get(context.Background(), uuid.NewString())
+	require.Error(t, err)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	requests := 0
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	u, err := upstash.New(upstash.Options{Transport: transport})
+	require.NoError(t, err)
+
+	_, err = u.Get(context.Background(), uuid.NewString())
+	require.ErrorIs(t, err, upstash.ErrNil)
+	require.Equal(t, 1, requests)
+
+	_, err = upstash.New(upstash.Options{Transport: transport, TLSConfig: &tls.Config{}})
+	require.Error(t, err)
+}
+
+func TestWrongType(t *testing.T) {
+	key := uuid.NewString()
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	_, err := u.LPush(ctx, key, []string{"a"})
+	require.NoError(t, err)
+
+	_, err = u.Incr(ctx, key)
+	var redisErr *client.RedisError
+	require.True(t, errors.As(err, &redisErr))
+	require.Equal(t, "WRONGTYPE", redisErr.Prefix)
+}
+
+func TestUnauthorized(t *testing.T) {
+	u, _ := upstash.New(upstash.Options{Token: "invalid"})
+
+	_, err := u.Get(context.Background(), uuid.NewString())
+	require.True(t, errors.Is(err, client.ErrUnauthorized))
+}
+
+func TestSetBytes(t *testing.T) {
+	key := uuid.NewString()
+	value := []byte{0x00, 0xff, 0xfe, '"', '\n', 0x80, 'a'}
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.SetBytes(ctx, key, value)
+	require.NoError(t, err)
+
+	got, err := u.GetBytes(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	length, err := u.StrLen(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(value)), length)
+
+	_, err = u.GetBytes(ctx, uuid.NewString())
+	require.ErrorIs(t, err, upstash.ErrNil)
+}
+
+func TestSetEXBytes(t *testing.T) {
+	key := uuid.NewString()
+	value := []byte{0xc3, 0x28, 0x00}
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.SetEXBytes(ctx, key, 10, value)
+	require.NoError(t, err)
+
+	got, err := u.GetBytes(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	ttl, err := u.TTL(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, ttl)
+}
+
+func TestUnicode(t *testing.T) {
+	key := uuid.NewString()
+	value := "héllo wörld 👋 OK"
+	u, _ := upstash.New(upstash.Options{})
+	ctx := context.Background()
+
+	err := u.Set(ctx, key, value)
+	require.NoError(t, err)
+
+	got, err := u.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, value, got)
+
+	err = u.Set(ctx, key, "OK")
+	require.NoError(t, err)
+
+	got, err = u.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "OK", got)
+}
+
+func TestReadYourWrites(t *testing.T) {
+	key := uuid.NewString()
+	u, err := upstash.New(upstash.Options{ReadYourWrites: true, ReadFromEdge: true})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = u.Set(ctx, key, "value")
+	require.NoError(t, err)
+
+	got, err := u.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "value", got)
 }
@@ -0,0 +1,172 @@
+package upstashtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type command struct {
+	// Number of arguments including the command name. Negative values
+	// declare a minimum.
+	arity int
+	fn    func(s *Server, args []string) (interface{}, error)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{}
+	for _, table := range []map[string]command{
+		stringCommands,
+		genericCommands,
+		hashCommands,
+		listCommands,
+		setCommands,
+		zsetCommands,
+		scriptingCommands,
+	} {
+		for name, cmd := range table {
+			commands[name] = cmd
+		}
+	}
+}
+
+// Validate the command name and number of arguments.
+func checkCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("ERR empty command")
+	}
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("ERR unknown command '%s'", args[0])
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		return fmt.Errorf("ERR wrong number of arguments for '%s' command", name)
+	}
+	return nil
+}
+
+// Execute a command, the caller must hold s.mu.
+func (s *Server) exec(args []string) (interface{}, error) {
+	if err := checkCommand(args); err != nil {
+		return nil, err
+	}
+	return commands[strings.ToLower(args[0])].fn(s, args[1:])
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errNotInt
+	}
+	return n, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		s = "+Inf"
+	case "-inf":
+		s = "-Inf"
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errNotFloat
+	}
+	return f, nil
+}
+
+// Format a float the way Redis does in replies.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Resolve a possibly negative index range against a sequence of length n.
+// Returns an empty range when nothing is selected.
+func normalizeRange(start, stop int64, n int) (int, int) {
+	if start < 0 {
+		start += int64(n)
+	}
+	if stop < 0 {
+		stop += int64(n)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= int64(n) {
+		stop = int64(n) - 1
+	}
+	if start > stop || start >= int64(n) {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Parse the MATCH, COUNT and TYPE options shared by the scan commands.
+func parseScanOptions(args []string, allowType bool) (match string, count int, typ string, err error) {
+	count = 10
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			return "", 0, "", errSyntax
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			match = args[i+1]
+		case "count":
+			n, err := parseInt(args[i+1])
+			if err != nil || n < 1 {
+				return "", 0, "", errSyntax
+			}
+			count = int(n)
+		case "type":
+			if !allowType {
+				return "", 0, "", errSyntax
+			}
+			typ = strings.ToLower(args[i+1])
+		default:
+			return "", 0, "", errSyntax
+		}
+		i++
+	}
+	return match, count, typ, nil
+}
+
+// Page through a sorted list of items using the cursor as offset.
+func scanPage(items []string, cursor string, count int) (string, []string, error) {
+	offset, err := strconv.Atoi(cursor)
+	if err != nil || offset < 0 {
+		return "", nil, fmt.Errorf("ERR invalid cursor")
+	}
+	if offset >= len(items) {
+		return "0", []string{}, nil
+	}
+	end := offset + count
+	if end >= len(items) {
+		return "0", items[offset:], nil
+	}
+	return strconv.Itoa(end), items[offset:end], nil
+}
+
+func errArity(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", name)
+}
+
+func errInvalidExpire(name string) error {
+	return fmt.Errorf("ERR invalid expire time in '%s' command", name)
+}
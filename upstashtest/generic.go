@@ -0,0 +1,277 @@
+package upstashtest
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+var genericCommands = map[string]command{
+	"del":    {-2, cmdDel},
+	"unlink": {-2, cmdDel},
+	"exists": {-2, func(s *Server, args []string) (interface{}, error) {
+		var n int64
+		for _, key := range args {
+			if s.db.exists(key) {
+				n++
+			}
+		}
+		return n, nil
+	}},
+	"touch": {-2, func(s *Server, args []string) (interface{}, error) {
+		var n int64
+		for _, key := range args {
+			if s.db.exists(key) {
+				n++
+			}
+		}
+		return n, nil
+	}},
+	"expire":    {-3, cmdExpire(func(n int64) time.Time { return time.Now().Add(time.Duration(n) * time.Second) })},
+	"pexpire":   {-3, cmdExpire(func(n int64) time.Time { return time.Now().Add(time.Duration(n) * time.Millisecond) })},
+	"expireat":  {-3, cmdExpire(func(n int64) time.Time { return time.Unix(n, 0) })},
+	"pexpireat": {-3, cmdExpire(func(n int64) time.Time { return time.Unix(0, n*int64(time.Millisecond)) })},
+	"ttl":       {2, cmdTTL(time.Second)},
+	"pttl":      {2, cmdTTL(time.Millisecond)},
+	"persist": {2, func(s *Server, args []string) (interface{}, error) {
+		e := s.db.get(args[0])
+		if e == nil || e.expireAt.IsZero() {
+			return int64(0), nil
+		}
+		e.expireAt = time.Time{}
+		return int64(1), nil
+	}},
+	"type": {2, func(s *Server, args []string) (interface{}, error) {
+		e := s.db.get(args[0])
+		if e == nil {
+			return status("none"), nil
+		}
+		return status(typeName(e.value)), nil
+	}},
+	"rename": {3, func(s *Server, args []string) (interface{}, error) {
+		e := s.db.get(args[0])
+		if e == nil {
+			return nil, errNoSuchKey
+		}
+		delete(s.db.entries, args[0])
+		s.db.entries[args[1]] = e
+		return status("OK"), nil
+	}},
+	"renamenx": {3, func(s *Server, args []string) (interface{}, error) {
+		e := s.db.get(args[0])
+		if e == nil {
+			return nil, errNoSuchKey
+		}
+		if s.db.exists(args[1]) {
+			return int64(0), nil
+		}
+		delete(s.db.entries, args[0])
+		s.db.entries[args[1]] = e
+		return int64(1), nil
+	}},
+	"copy": {-3, func(s *Server, args []string) (interface{}, error) {
+		replace := false
+		for _, opt := range args[2:] {
+			if strings.ToLower(opt) != "replace" {
+				return nil, errSyntax
+			}
+			replace = true
+		}
+		e := s.db.get(args[0])
+		if e == nil || (!replace && s.db.exists(args[1])) {
+			return int64(0), nil
+		}
+		s.db.entries[args[1]] = &entry{value: copyValue(e.value), expireAt: e.expireAt}
+		return int64(1), nil
+	}},
+	"randomkey": {1, func(s *Server, args []string) (interface{}, error) {
+		keys := s.db.keys()
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		return keys[rand.Intn(len(keys))], nil
+	}},
+	"keys": {2, func(s *Server, args []string) (interface{}, error) {
+		matched := []string{}
+		for _, key := range s.db.keys() {
+			if globMatch(args[0], key) {
+				matched = append(matched, key)
+			}
+		}
+		return matched, nil
+	}},
+	"scan": {-2, func(s *Server, args []string) (interface{}, error) {
+		match, count, typ, err := parseScanOptions(args[1:], true)
+		if err != nil {
+			return nil, err
+		}
+		cursor, page, err := scanPage(s.db.keys(), args[0], count)
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{}
+		for _, key := range page {
+			e := s.db.get(key)
+			if e == nil || (match != "" && !globMatch(match, key)) || (typ != "" && typeName(e.value) != typ) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		return []interface{}{cursor, keys}, nil
+	}},
+	"dbsize": {1, func(s *Server, args []string) (interface{}, error) {
+		return int64(len(s.db.keys())), nil
+	}},
+	"flushall": {-1, cmdFlush},
+	"flushdb":  {-1, cmdFlush},
+	"ping": {-1, func(s *Server, args []string) (interface{}, error) {
+		if len(args) > 0 {
+			return args[0], nil
+		}
+		return status("PONG"), nil
+	}},
+	"echo": {2, func(s *Server, args []string) (interface{}, error) {
+		return args[0], nil
+	}},
+}
+
+func cmdDel(s *Server, args []string) (interface{}, error) {
+	var n int64
+	for _, key := range args {
+		if s.db.del(key) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func cmdFlush(s *Server, args []string) (interface{}, error) {
+	s.db = newKeyspace()
+	return status("OK"), nil
+}
+
+func cmdExpire(at func(n int64) time.Time) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var nx, xx, gt, lt bool
+		for _, opt := range args[2:] {
+			switch strings.ToLower(opt) {
+			case "nx":
+				nx = true
+			case "xx":
+				xx = true
+			case "gt":
+				gt = true
+			case "lt":
+				lt = true
+			default:
+				return nil, errSyntax
+			}
+		}
+		e := s.db.get(args[0])
+		if e == nil {
+			return int64(0), nil
+		}
+		expireAt := at(n)
+		hasTTL := !e.expireAt.IsZero()
+		switch {
+		case nx && hasTTL,
+			xx && !hasTTL,
+			gt && (!hasTTL || !expireAt.After(e.expireAt)),
+			lt && hasTTL && !expireAt.Before(e.expireAt):
+			return int64(0), nil
+		}
+		if !expireAt.After(time.Now()) {
+			s.db.del(args[0])
+			return int64(1), nil
+		}
+		e.expireAt = expireAt
+		return int64(1), nil
+	}
+}
+
+func cmdTTL(unit time.Duration) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		e := s.db.get(args[0])
+		if e == nil {
+			return int64(-2), nil
+		}
+		if e.expireAt.IsZero() {
+			return int64(-1), nil
+		}
+		remaining := time.Until(e.expireAt)
+		// Round up like Redis does, a key with 1.5s left reports 2s.
+		return int64((remaining + unit - 1) / unit), nil
+	}
+}
+
+// Match key against a Redis glob-style pattern.
+func globMatch(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if globMatch(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return pattern == key
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			matched := false
+			for i := 0; i < len(class); i++ {
+				if i+2 < len(class) && class[i+1] == '-' {
+					if class[i] <= key[0] && key[0] <= class[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if class[i] == key[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[end+2:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,266 @@
+package upstashtest
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hashCommands = map[string]command{
+	"hset": {-4, func(s *Server, args []string) (interface{}, error) {
+		if len(args)%2 != 1 {
+			return nil, errArity("hset")
+		}
+		h, err := s.db.getHash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		var added int64
+		for i := 1; i < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				added++
+			}
+			h[args[i]] = args[i+1]
+		}
+		return added, nil
+	}},
+	"hmset": {-4, func(s *Server, args []string) (interface{}, error) {
+		if len(args)%2 != 1 {
+			return nil, errArity("hmset")
+		}
+		h, err := s.db.getHash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(args); i += 2 {
+			h[args[i]] = args[i+1]
+		}
+		return status("OK"), nil
+	}},
+	"hsetnx": {4, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := h[args[1]]; ok {
+			return int64(0), nil
+		}
+		h[args[1]] = args[2]
+		return int64(1), nil
+	}},
+	"hget": {3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := h[args[1]]; ok {
+			return v, nil
+		}
+		return nil, nil
+	}},
+	"hmget": {-3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(args)-1)
+		for i, field := range args[1:] {
+			if v, ok := h[field]; ok {
+				values[i] = v
+			}
+		}
+		return values, nil
+	}},
+	"hgetall": {2, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		values := []string{}
+		for _, field := range h.fields() {
+			values = append(values, field, h[field])
+		}
+		return values, nil
+	}},
+	"hdel": {-3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		var n int64
+		for _, field := range args[1:] {
+			if _, ok := h[field]; ok {
+				delete(h, field)
+				n++
+			}
+		}
+		s.db.cleanup(args[0])
+		return n, nil
+	}},
+	"hexists": {3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := h[args[1]]
+		return boolToInt(ok), nil
+	}},
+	"hkeys": {2, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		return h.fields(), nil
+	}},
+	"hvals": {2, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		values := []string{}
+		for _, field := range h.fields() {
+			values = append(values, h[field])
+		}
+		return values, nil
+	}},
+	"hlen": {2, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		return int64(len(h)), err
+	}},
+	"hstrlen": {3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		return int64(len(h[args[1]])), err
+	}},
+	"hincrby": {4, func(s *Server, args []string) (interface{}, error) {
+		increment, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		h, err := s.db.getHash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		var current int64
+		if v, ok := h[args[1]]; ok {
+			if current, err = parseInt(v); err != nil {
+				return nil, errHashNotInt
+			}
+		}
+		current += increment
+		h[args[1]] = strconv.FormatInt(current, 10)
+		return current, nil
+	}},
+	"hincrbyfloat": {4, func(s *Server, args []string) (interface{}, error) {
+		increment, err := parseFloat(args[2])
+		if err != nil {
+			return nil, err
+		}
+		h, err := s.db.getHash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		current := 0.0
+		if v, ok := h[args[1]]; ok {
+			if current, err = parseFloat(v); err != nil {
+				return nil, errHashNotFloat
+			}
+		}
+		result := formatFloat(current + increment)
+		h[args[1]] = result
+		return result, nil
+	}},
+	"hrandfield": {-2, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		fields := h.fields()
+		if len(args) == 1 {
+			if len(fields) == 0 {
+				return nil, nil
+			}
+			return fields[rand.Intn(len(fields))], nil
+		}
+		count, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		withValues := false
+		if len(args) == 3 {
+			if strings.ToLower(args[2]) != "withvalues" {
+				return nil, errSyntax
+			}
+			withValues = true
+		} else if len(args) > 3 {
+			return nil, errSyntax
+		}
+		picked := randomMembers(fields, count)
+		if !withValues {
+			return picked, nil
+		}
+		values := make([]string, 0, len(picked)*2)
+		for _, field := range picked {
+			values = append(values, field, h[field])
+		}
+		return values, nil
+	}},
+	"hscan": {-3, func(s *Server, args []string) (interface{}, error) {
+		h, err := s.db.getHash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		match, count, _, err := parseScanOptions(args[2:], false)
+		if err != nil {
+			return nil, err
+		}
+		cursor, page, err := scanPage(h.fields(), args[1], count)
+		if err != nil {
+			return nil, err
+		}
+		values := []string{}
+		for _, field := range page {
+			if match == "" || globMatch(match, field) {
+				values = append(values, field, h[field])
+			}
+		}
+		return []interface{}{cursor, values}, nil
+	}},
+}
+
+var (
+	errHashNotInt   = errors.New("ERR hash value is not an integer")
+	errHashNotFloat = errors.New("ERR hash value is not a float")
+)
+
+func (h hashValue) fields() []string {
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Pick members at random following the semantics of SRANDMEMBER and
+// HRANDFIELD: a positive count returns distinct members, a negative count
+// allows repetitions.
+func randomMembers(members []string, count int64) []string {
+	picked := []string{}
+	if count < 0 {
+		if len(members) == 0 {
+			return picked
+		}
+		for i := int64(0); i < -count; i++ {
+			picked = append(picked, members[rand.Intn(len(members))])
+		}
+		return picked
+	}
+	shuffled := append([]string{}, members...)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	if int(count) < len(shuffled) {
+		shuffled = shuffled[:count]
+	}
+	return append(picked, shuffled...)
+}
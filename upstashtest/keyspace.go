@@ -0,0 +1,234 @@
+package upstashtest
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// A simple string reply, e.g. `OK`, as opposed to a bulk string.
+type status string
+
+var (
+	errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errSyntax    = errors.New("ERR syntax error")
+	errNotInt    = errors.New("ERR value is not an integer or out of range")
+	errNotFloat  = errors.New("ERR value is not a valid float")
+	errNoSuchKey = errors.New("ERR no such key")
+)
+
+type (
+	hashValue map[string]string
+	listValue struct{ items []string }
+	setValue  map[string]struct{}
+	zsetValue map[string]float64
+)
+
+type entry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+type keyspace struct {
+	entries map[string]*entry
+}
+
+func newKeyspace() *keyspace {
+	return &keyspace{entries: map[string]*entry{}}
+}
+
+// Return the live entry at key, evicting it first when it has expired.
+func (k *keyspace) get(key string) *entry {
+	e, ok := k.entries[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		delete(k.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (k *keyspace) exists(key string) bool {
+	return k.get(key) != nil
+}
+
+// Store value at key, discarding any previous time to live.
+func (k *keyspace) set(key string, value interface{}) {
+	k.entries[key] = &entry{value: value}
+}
+
+func (k *keyspace) del(key string) bool {
+	if k.get(key) == nil {
+		return false
+	}
+	delete(k.entries, key)
+	return true
+}
+
+// Sorted list of all live keys.
+func (k *keyspace) keys() []string {
+	keys := make([]string, 0, len(k.entries))
+	for key := range k.entries {
+		if k.get(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (k *keyspace) getString(key string) (string, bool, error) {
+	e := k.get(key)
+	if e == nil {
+		return "", false, nil
+	}
+	s, ok := e.value.(string)
+	if !ok {
+		return "", false, errWrongType
+	}
+	return s, true, nil
+}
+
+// Overwrite the string at key while keeping its time to live.
+func (k *keyspace) updateString(key string, value string) {
+	if e := k.get(key); e != nil {
+		e.value = value
+		return
+	}
+	k.set(key, value)
+}
+
+func (k *keyspace) getHash(key string, create bool) (hashValue, error) {
+	e := k.get(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		h := hashValue{}
+		k.set(key, h)
+		return h, nil
+	}
+	h, ok := e.value.(hashValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return h, nil
+}
+
+func (k *keyspace) getList(key string, create bool) (*listValue, error) {
+	e := k.get(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		l := &listValue{}
+		k.set(key, l)
+		return l, nil
+	}
+	l, ok := e.value.(*listValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return l, nil
+}
+
+func (k *keyspace) getSet(key string, create bool) (setValue, error) {
+	e := k.get(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		s := setValue{}
+		k.set(key, s)
+		return s, nil
+	}
+	s, ok := e.value.(setValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return s, nil
+}
+
+func (k *keyspace) getZSet(key string, create bool) (zsetValue, error) {
+	e := k.get(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		z := zsetValue{}
+		k.set(key, z)
+		return z, nil
+	}
+	z, ok := e.value.(zsetValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return z, nil
+}
+
+// Remove key when its collection value became empty.
+func (k *keyspace) cleanup(key string) {
+	e := k.get(key)
+	if e == nil {
+		return
+	}
+	empty := false
+	switch v := e.value.(type) {
+	case hashValue:
+		empty = len(v) == 0
+	case *listValue:
+		empty = len(v.items) == 0
+	case setValue:
+		empty = len(v) == 0
+	case zsetValue:
+		empty = len(v) == 0
+	}
+	if empty {
+		delete(k.entries, key)
+	}
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case hashValue:
+		return "hash"
+	case *listValue:
+		return "list"
+	case setValue:
+		return "set"
+	case zsetValue:
+		return "zset"
+	}
+	return "none"
+}
+
+// Deep copy a value, used by COPY.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case hashValue:
+		c := hashValue{}
+		for f, val := range v {
+			c[f] = val
+		}
+		return c
+	case *listValue:
+		return &listValue{items: append([]string{}, v.items...)}
+	case setValue:
+		c := setValue{}
+		for m := range v {
+			c[m] = struct{}{}
+		}
+		return c
+	case zsetValue:
+		c := zsetValue{}
+		for m, s := range v {
+			c[m] = s
+		}
+		return c
+	}
+	return value
+}
@@ -0,0 +1,353 @@
+package upstashtest
+
+import (
+	"errors"
+	"strings"
+)
+
+var errIndexOutOfRange = errors.New("ERR index out of range")
+
+var listCommands = map[string]command{
+	"lpush":  {-3, cmdPush(true, false)},
+	"rpush":  {-3, cmdPush(false, false)},
+	"lpushx": {-3, cmdPush(true, true)},
+	"rpushx": {-3, cmdPush(false, true)},
+	"lpop":   {-2, cmdPop(true)},
+	"rpop":   {-2, cmdPop(false)},
+	"llen": {2, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil || l == nil {
+			return int64(0), err
+		}
+		return int64(len(l.items)), nil
+	}},
+	"lrange": {4, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return []string{}, nil
+		}
+		from, to := normalizeRange(start, stop, len(l.items))
+		return append([]string{}, l.items[from:to]...), nil
+	}},
+	"lindex": {3, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		index, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return nil, nil
+		}
+		i, ok := listIndex(l, index)
+		if !ok {
+			return nil, nil
+		}
+		return l.items[i], nil
+	}},
+	"lset": {4, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		index, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return nil, errNoSuchKey
+		}
+		i, ok := listIndex(l, index)
+		if !ok {
+			return nil, errIndexOutOfRange
+		}
+		l.items[i] = args[2]
+		return status("OK"), nil
+	}},
+	"lrem": {4, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		count, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return int64(0), nil
+		}
+		var removed int64
+		limit := count
+		if limit < 0 {
+			limit = -limit
+		}
+		keep := make([]string, 0, len(l.items))
+		if count >= 0 {
+			for _, item := range l.items {
+				if item == args[2] && (limit == 0 || removed < limit) {
+					removed++
+					continue
+				}
+				keep = append(keep, item)
+			}
+		} else {
+			for i := len(l.items) - 1; i >= 0; i-- {
+				if l.items[i] == args[2] && removed < limit {
+					removed++
+					continue
+				}
+				keep = append([]string{l.items[i]}, keep...)
+			}
+		}
+		l.items = keep
+		s.db.cleanup(args[0])
+		return removed, nil
+	}},
+	"ltrim": {4, func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return status("OK"), nil
+		}
+		from, to := normalizeRange(start, stop, len(l.items))
+		l.items = append([]string{}, l.items[from:to]...)
+		s.db.cleanup(args[0])
+		return status("OK"), nil
+	}},
+	"linsert": {5, func(s *Server, args []string) (interface{}, error) {
+		var after bool
+		switch strings.ToLower(args[1]) {
+		case "before":
+		case "after":
+			after = true
+		default:
+			return nil, errSyntax
+		}
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return int64(0), nil
+		}
+		for i, item := range l.items {
+			if item != args[2] {
+				continue
+			}
+			if after {
+				i++
+			}
+			l.items = append(l.items[:i], append([]string{args[3]}, l.items[i:]...)...)
+			return int64(len(l.items)), nil
+		}
+		return int64(-1), nil
+	}},
+	"lpos": {-3, func(s *Server, args []string) (interface{}, error) {
+		rank, count, maxLen := int64(1), int64(-1), int64(0)
+		for i := 2; i < len(args); i += 2 {
+			if i+1 >= len(args) {
+				return nil, errSyntax
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(args[i]) {
+			case "rank":
+				if n == 0 {
+					return nil, errors.New("ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+				}
+				rank = n
+			case "count":
+				if n < 0 {
+					return nil, errors.New("ERR COUNT can't be negative")
+				}
+				count = n
+			case "maxlen":
+				if n < 0 {
+					return nil, errors.New("ERR MAXLEN can't be negative")
+				}
+				maxLen = n
+			default:
+				return nil, errSyntax
+			}
+		}
+		l, err := s.db.getList(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		var items []string
+		if l != nil {
+			items = l.items
+		}
+
+		matches := []int64{}
+		skip := rank - 1
+		step, start := 1, 0
+		if rank < 0 {
+			skip = -rank - 1
+			step, start = -1, len(items)-1
+		}
+		for i, compared := start, int64(0); i >= 0 && i < len(items); i += step {
+			if maxLen > 0 && compared >= maxLen {
+				break
+			}
+			compared++
+			if items[i] != args[1] {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			matches = append(matches, int64(i))
+			if count >= 0 && (count == 0 || int64(len(matches)) < count) {
+				continue
+			}
+			break
+		}
+		if count < 0 {
+			if len(matches) == 0 {
+				return nil, nil
+			}
+			return matches[0], nil
+		}
+		result := make([]interface{}, len(matches))
+		for i, m := range matches {
+			result[i] = m
+		}
+		return result, nil
+	}},
+	"lmove": {5, func(s *Server, args []string) (interface{}, error) {
+		return listMove(s, args[0], args[1], args[2], args[3])
+	}},
+	"rpoplpush": {3, func(s *Server, args []string) (interface{}, error) {
+		return listMove(s, args[0], args[1], "right", "left")
+	}},
+}
+
+func listIndex(l *listValue, index int64) (int, bool) {
+	if index < 0 {
+		index += int64(len(l.items))
+	}
+	if index < 0 || index >= int64(len(l.items)) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+func cmdPush(left bool, onlyExisting bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		l, err := s.db.getList(args[0], !onlyExisting)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return int64(0), nil
+		}
+		for _, v := range args[1:] {
+			if left {
+				l.items = append([]string{v}, l.items...)
+			} else {
+				l.items = append(l.items, v)
+			}
+		}
+		return int64(len(l.items)), nil
+	}
+}
+
+func cmdPop(left bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		if len(args) > 2 {
+			return nil, errSyntax
+		}
+		count := int64(-1)
+		if len(args) == 2 {
+			n, err := parseInt(args[1])
+			if err != nil || n < 0 {
+				return nil, errors.New("ERR value is out of range, must be positive")
+			}
+			count = n
+		}
+		l, err := s.db.getList(args[0], false)
+		if err != nil || l == nil {
+			return nil, err
+		}
+		n := count
+		if n < 0 {
+			n = 1
+		}
+		if n > int64(len(l.items)) {
+			n = int64(len(l.items))
+		}
+		var popped []string
+		if left {
+			popped = append([]string{}, l.items[:n]...)
+			l.items = l.items[n:]
+		} else {
+			for i := int64(0); i < n; i++ {
+				popped = append(popped, l.items[len(l.items)-1])
+				l.items = l.items[:len(l.items)-1]
+			}
+		}
+		s.db.cleanup(args[0])
+		if count < 0 {
+			return popped[0], nil
+		}
+		return popped, nil
+	}
+}
+
+func listMove(s *Server, source, destination, from, to string) (interface{}, error) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if (from != "left" && from != "right") || (to != "left" && to != "right") {
+		return nil, errSyntax
+	}
+	src, err := s.db.getList(source, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.getList(destination, false); err != nil {
+		return nil, err
+	}
+	if src == nil || len(src.items) == 0 {
+		return nil, nil
+	}
+	var v string
+	if from == "left" {
+		v, src.items = src.items[0], src.items[1:]
+	} else {
+		v, src.items = src.items[len(src.items)-1], src.items[:len(src.items)-1]
+	}
+	s.db.cleanup(source)
+	dst, _ := s.db.getList(destination, true)
+	if to == "left" {
+		dst.items = append([]string{v}, dst.items...)
+	} else {
+		dst.items = append(dst.items, v)
+	}
+	return v, nil
+}
@@ -0,0 +1,221 @@
+package upstashtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ScriptFunc emulates a Lua script. The fake server only interprets simple
+// `return ...` scripts, tests register a Go implementation for any other
+// script they use.
+//
+// call executes a command against the keyspace like `redis.call` does.
+// Return values follow the Lua to Redis conversion rules: strings become
+// bulk strings, int64 integers, nil a nil reply and []interface{} arrays.
+type ScriptFunc func(call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, error)
+
+var errNoScript = errors.New("NOSCRIPT No matching script. Please use EVAL.")
+
+// HandleScript registers fn as the implementation of the Lua script source.
+func (s *Server) HandleScript(source string, fn ScriptFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[source] = fn
+}
+
+var scriptingCommands = map[string]command{
+	"eval": {-3, func(s *Server, args []string) (interface{}, error) {
+		s.scripts[sha1Hex(args[0])] = args[0]
+		return s.runScript(args[0], args[1:])
+	}},
+	"evalsha": {-3, func(s *Server, args []string) (interface{}, error) {
+		source, ok := s.scripts[strings.ToLower(args[0])]
+		if !ok {
+			return nil, errNoScript
+		}
+		return s.runScript(source, args[1:])
+	}},
+	"script": {-2, func(s *Server, args []string) (interface{}, error) {
+		switch strings.ToLower(args[0]) {
+		case "load":
+			if len(args) != 2 {
+				return nil, errArity("script|load")
+			}
+			sha := sha1Hex(args[1])
+			s.scripts[sha] = args[1]
+			return sha, nil
+		case "exists":
+			result := make([]interface{}, len(args)-1)
+			for i, sha := range args[1:] {
+				_, ok := s.scripts[strings.ToLower(sha)]
+				result[i] = boolToInt(ok)
+			}
+			return result, nil
+		case "flush":
+			s.scripts = map[string]string{}
+			return status("OK"), nil
+		}
+		return nil, fmt.Errorf("ERR unknown subcommand '%s'", args[0])
+	}},
+}
+
+func (s *Server) runScript(source string, args []string) (interface{}, error) {
+	numKeys, err := parseInt(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if numKeys < 0 || int(numKeys) > len(args)-1 {
+		return nil, errors.New("ERR Number of keys can't be greater than number of args")
+	}
+	keys := args[1 : 1+numKeys]
+	call := func(args ...string) (interface{}, error) {
+		return s.exec(args)
+	}
+	fn, ok := s.handlers[source]
+	if !ok {
+		res, ok, err := evalSimple(source, call, keys, args[1+numKeys:])
+		if !ok {
+			return nil, errors.New("ERR upstashtest: no handler registered for script")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ERR Error running script: %w", err)
+		}
+		return res, nil
+	}
+	res, err := fn(call, keys, args[1+numKeys:])
+	if err != nil {
+		return nil, fmt.Errorf("ERR Error running script: %w", err)
+	}
+	return res, nil
+}
+
+func sha1Hex(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// evalSimple interprets scripts of the form `return <expr>` where expr is a
+// string or number literal, KEYS[n], ARGV[n], a table `{expr, ...}` or a
+// `redis.call(expr, ...)`. Anything else requires HandleScript.
+func evalSimple(source string, call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, bool, error) {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if idx := strings.Index(line, "--"); idx >= 0 {
+			lines[i] = line[:idx]
+		}
+	}
+	src := strings.TrimSpace(strings.Join(lines, "\n"))
+	if !strings.HasPrefix(src, "return ") {
+		return nil, false, nil
+	}
+	p := &luaParser{src: strings.TrimSpace(strings.TrimPrefix(src, "return ")), call: call, keys: keys, args: args}
+	v, err := p.expr()
+	if err != nil {
+		return nil, true, err
+	}
+	p.skip()
+	if p.pos != len(p.src) {
+		return nil, false, nil
+	}
+	return v, true, nil
+}
+
+type luaParser struct {
+	src  string
+	pos  int
+	call func(args ...string) (interface{}, error)
+	keys []string
+	args []string
+}
+
+var errUnsupported = errors.New("unsupported")
+
+func (p *luaParser) skip() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\n' || p.src[p.pos] == '\t' || p.src[p.pos] == ';') {
+		p.pos++
+	}
+}
+
+func (p *luaParser) list(end byte) ([]interface{}, error) {
+	values := []interface{}{}
+	for {
+		p.skip()
+		if p.pos < len(p.src) && p.src[p.pos] == end {
+			p.pos++
+			return values, nil
+		}
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skip()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *luaParser) index(values []string) (interface{}, error) {
+	end := strings.IndexByte(p.src[p.pos:], ']')
+	if end < 0 {
+		return nil, errUnsupported
+	}
+	n, err := parseInt(p.src[p.pos : p.pos+end])
+	p.pos += end + 1
+	if err != nil || n < 1 || int(n) > len(values) {
+		return nil, nil
+	}
+	return values[n-1], nil
+}
+
+func (p *luaParser) expr() (interface{}, error) {
+	p.skip()
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "KEYS["):
+		p.pos += 5
+		return p.index(p.keys)
+	case strings.HasPrefix(rest, "ARGV["):
+		p.pos += 5
+		return p.index(p.args)
+	case strings.HasPrefix(rest, "redis.call("):
+		p.pos += len("redis.call(")
+		values, err := p.list(')')
+		if err != nil {
+			return nil, err
+		}
+		cmd := make([]string, len(values))
+		for i, v := range values {
+			cmd[i] = fmt.Sprint(v)
+		}
+		res, err := p.call(cmd...)
+		if st, ok := res.(status); ok {
+			res = string(st)
+		}
+		return res, err
+	case strings.HasPrefix(rest, "{"):
+		p.pos++
+		return p.list('}')
+	case strings.HasPrefix(rest, "'") || strings.HasPrefix(rest, `"`):
+		quote := rest[0]
+		end := strings.IndexByte(rest[1:], quote)
+		if end < 0 {
+			return nil, errUnsupported
+		}
+		p.pos += end + 2
+		return rest[1 : end+1], nil
+	}
+	end := 0
+	for end < len(rest) && (rest[end] == '-' || (rest[end] >= '0' && rest[end] <= '9')) {
+		end++
+	}
+	if end == 0 {
+		return nil, errUnsupported
+	}
+	p.pos += end
+	return parseInt(rest[:end])
+}
@@ -0,0 +1,295 @@
+// Package upstashtest provides an in-memory fake of the Upstash REST API.
+//
+// The fake speaks the same protocol as the upstash client: path-style GET
+// requests, JSON-array POST bodies, the `/pipeline` and `/multi-exec`
+// endpoints, bearer token authentication and base64 response encoding.
+// It is backed by an in-memory keyspace with TTL support and is intended for
+// tests that should run without access to a real Upstash database.
+package upstashtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is a fake Upstash database listening on a local HTTP port.
+type Server struct {
+	// Base url of the server, to be used as `upstash.Options.Url`.
+	URL string
+
+	// Token clients must send as bearer token.
+	Token string
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	db        *keyspace
+	scripts   map[string]string
+	handlers  map[string]ScriptFunc
+	syncToken int64
+}
+
+// NewServer starts and returns a new fake server. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Token:    "upstashtest",
+		db:       newKeyspace(),
+		scripts:  map[string]string{},
+		handlers: map[string]ScriptFunc{},
+	}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on
+// this server have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// FlushAll removes all keys and loaded scripts.
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.db = newKeyspace()
+	s.scripts = map[string]string{}
+}
+
+// Do executes a single command directly against the keyspace, bypassing
+// HTTP. Useful to prepare or inspect state in tests.
+func (s *Server) Do(args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exec(args)
+}
+
+type response struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, response{Error: "Unauthorized"})
+		return
+	}
+
+	path, err := splitPath(r.URL.EscapedPath())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Error: err.Error()})
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Error: err.Error()})
+		return
+	}
+	encode := strings.EqualFold(r.Header.Get("Upstash-Encoding"), "base64")
+
+	if r.Method == http.MethodPost && len(path) == 1 && (path[0] == "pipeline" || path[0] == "multi-exec") {
+		var commands [][]interface{}
+		if err := json.Unmarshal(body, &commands); err != nil {
+			writeJSON(w, http.StatusBadRequest, response{Error: "ERR failed to parse pipeline request"})
+			return
+		}
+		cmds := make([][]string, len(commands))
+		for i, c := range commands {
+			cmds[i] = stringArgs(c)
+		}
+		var (
+			responses []response
+			status    int
+		)
+		if path[0] == "pipeline" {
+			responses, status = s.pipeline(cmds, encode)
+		} else {
+			responses, status = s.multiExec(cmds, encode)
+		}
+		if status != http.StatusOK {
+			writeJSON(w, status, responses[0])
+			return
+		}
+		s.writeSyncToken(w)
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+
+	var args []string
+	switch r.Method {
+	case http.MethodGet:
+		args = path
+	case http.MethodPost:
+		args = path
+		if len(path) == 0 {
+			var command []interface{}
+			if err := json.Unmarshal(body, &command); err != nil {
+				writeJSON(w, http.StatusBadRequest, response{Error: "ERR failed to parse command"})
+				return
+			}
+			args = stringArgs(command)
+		} else if len(body) > 0 {
+			args = append(args, string(body))
+		}
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, response{Error: "Method not allowed"})
+		return
+	}
+
+	s.mu.Lock()
+	res, err := s.exec(args)
+	s.mu.Unlock()
+	s.writeSyncToken(w)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": encodeResult(res, encode)})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if r.Header.Get("Authorization") == "Bearer "+s.Token {
+		return true
+	}
+	return r.URL.Query().Get("_token") == s.Token
+}
+
+func (s *Server) writeSyncToken(w http.ResponseWriter) {
+	s.mu.Lock()
+	s.syncToken++
+	token := s.syncToken
+	s.mu.Unlock()
+	w.Header().Set("Upstash-Sync-Token", strconv.FormatInt(token, 10))
+}
+
+func (s *Server) pipeline(cmds [][]string, encode bool) ([]response, int) {
+	responses := make([]response, len(cmds))
+	for i, args := range cmds {
+		s.mu.Lock()
+		res, err := s.exec(args)
+		s.mu.Unlock()
+		responses[i] = newResponse(res, err, encode)
+	}
+	return responses, http.StatusOK
+}
+
+func (s *Server) multiExec(cmds [][]string, encode bool) ([]response, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, args := range cmds {
+		if err := checkCommand(args); err != nil {
+			return []response{{Error: "EXECABORT Transaction discarded because of previous errors: " + err.Error()}}, http.StatusBadRequest
+		}
+	}
+	responses := make([]response, len(cmds))
+	for i, args := range cmds {
+		res, err := s.exec(args)
+		responses[i] = newResponse(res, err, encode)
+	}
+	return responses, http.StatusOK
+}
+
+func newResponse(res interface{}, err error, encode bool) response {
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+	r := response{Result: encodeResult(res, encode)}
+	if r.Result == nil {
+		// Keep explicit null results in the serialized response.
+		r.Result = json.RawMessage("null")
+	}
+	return r
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Split an escaped url path into its unescaped segments.
+func splitPath(escaped string) ([]string, error) {
+	escaped = strings.Trim(escaped, "/")
+	if escaped == "" {
+		return nil, nil
+	}
+	segments := strings.Split(escaped, "/")
+	for len(segments) > 0 && segments[len(segments)-1] == "" {
+		segments = segments[:len(segments)-1]
+	}
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, fmt.Errorf("ERR invalid path: %w", err)
+		}
+		segments[i] = unescaped
+	}
+	return segments, nil
+}
+
+func stringArgs(values []interface{}) []string {
+	args := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			args[i] = v
+		case float64:
+			args[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			args[i] = fmt.Sprint(v)
+		}
+	}
+	return args
+}
+
+// Convert a command reply into its JSON representation.
+func encodeResult(res interface{}, encode bool) interface{} {
+	switch v := res.(type) {
+	case nil:
+		return nil
+	case status:
+		if encode && v != "OK" {
+			return base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		return string(v)
+	case string:
+		if encode {
+			return base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		return v
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = encodeResult(e, encode)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = encodeResult(e, encode)
+		}
+		return out
+	default:
+		return fmt.Sprint(v)
+	}
+}
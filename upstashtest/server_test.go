@@ -0,0 +1,137 @@
+package upstashtest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chronark/upstash-go/upstashtest"
+	"github.com/stretchr/testify/require"
+)
+
+// Send a request to srv and decode the JSON response.
+func request(t *testing.T, srv *upstashtest.Server, method string, path string, body string, token string) (int, interface{}) {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var response interface{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+	return res.StatusCode, response
+}
+
+func TestAuthorization(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	status, _ := request(t, srv, "GET", "/get/key", "", "wrong")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = request(t, srv, "GET", "/get/key", "", srv.Token)
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestCommands(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	status, res := request(t, srv, "POST", "/", `["set", "a/b", "value"]`, srv.Token)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]interface{}{"result": "OK"}, res)
+
+	status, res = request(t, srv, "GET", "/get/a%2Fb", "", srv.Token)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]interface{}{"result": "value"}, res)
+
+	status, res = request(t, srv, "POST", "/set/raw", "raw value", srv.Token)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]interface{}{"result": "OK"}, res)
+
+	got, err := srv.Do("get", "raw")
+	require.NoError(t, err)
+	require.Equal(t, "raw value", got)
+
+	status, res = request(t, srv, "POST", "/", `["incr", "a/b"]`, srv.Token)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, map[string]interface{}{"error": "ERR value is not an integer or out of range"}, res)
+}
+
+func TestPipeline(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	status, res := request(t, srv, "POST", "/pipeline", `[["set", "key", "1"], ["incr", "key"], ["hget", "key", "field"]]`, srv.Token)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"result": "OK"},
+		map[string]interface{}{"result": float64(2)},
+		map[string]interface{}{"error": "WRONGTYPE Operation against a key holding the wrong kind of value"},
+	}, res)
+}
+
+func TestMultiExec(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	status, res := request(t, srv, "POST", "/multi-exec", `[["set", "key", "1"], ["get", "key"]]`, srv.Token)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"result": "OK"},
+		map[string]interface{}{"result": "1"},
+	}, res)
+
+	status, _ = request(t, srv, "POST", "/multi-exec", `[["set", "other", "1"], ["get"]]`, srv.Token)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	exists, err := srv.Do("exists", "other")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), exists)
+}
+
+func TestExpiration(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	_, err := srv.Do("set", "key", "value", "px", "50")
+	require.NoError(t, err)
+
+	ttl, err := srv.Do("pttl", "key")
+	require.NoError(t, err)
+	require.Greater(t, ttl, int64(0))
+
+	time.Sleep(100 * time.Millisecond)
+
+	got, err := srv.Do("get", "key")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestHandleScript(t *testing.T) {
+	srv := upstashtest.NewServer()
+	defer srv.Close()
+
+	script := `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	srv.HandleScript(script, func(call func(args ...string) (interface{}, error), keys []string, args []string) (interface{}, error) {
+		value, err := call("get", keys[0])
+		if err != nil || value != args[0] {
+			return int64(0), err
+		}
+		return call("del", keys[0])
+	})
+
+	_, err := srv.Do("set", "key", "token")
+	require.NoError(t, err)
+
+	res, err := srv.Do("eval", script, "1", "key", "other")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), res)
+
+	res, err = srv.Do("eval", script, "1", "key", "token")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res)
+}
@@ -0,0 +1,232 @@
+package upstashtest
+
+import (
+	"sort"
+)
+
+var setCommands = map[string]command{
+	"sadd": {-3, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		var added int64
+		for _, m := range args[1:] {
+			if _, ok := set[m]; !ok {
+				set[m] = struct{}{}
+				added++
+			}
+		}
+		return added, nil
+	}},
+	"srem": {-3, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		var removed int64
+		for _, m := range args[1:] {
+			if _, ok := set[m]; ok {
+				delete(set, m)
+				removed++
+			}
+		}
+		s.db.cleanup(args[0])
+		return removed, nil
+	}},
+	"smembers": {2, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		return set.members(), nil
+	}},
+	"sismember": {3, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := set[args[1]]
+		return boolToInt(ok), nil
+	}},
+	"smismember": {-3, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(args)-1)
+		for i, m := range args[1:] {
+			_, ok := set[m]
+			result[i] = boolToInt(ok)
+		}
+		return result, nil
+	}},
+	"scard": {2, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		return int64(len(set)), err
+	}},
+	"sinter":      {-2, cmdSetAlgebra(intersect, false)},
+	"sunion":      {-2, cmdSetAlgebra(union, false)},
+	"sdiff":       {-2, cmdSetAlgebra(difference, false)},
+	"sinterstore": {-3, cmdSetAlgebra(intersect, true)},
+	"sunionstore": {-3, cmdSetAlgebra(union, true)},
+	"sdiffstore":  {-3, cmdSetAlgebra(difference, true)},
+	"srandmember": {-2, func(s *Server, args []string) (interface{}, error) {
+		if len(args) > 2 {
+			return nil, errSyntax
+		}
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		members := set.members()
+		if len(args) == 1 {
+			if len(members) == 0 {
+				return nil, nil
+			}
+			return randomMembers(members, 1)[0], nil
+		}
+		count, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return randomMembers(members, count), nil
+	}},
+	"spop": {-2, func(s *Server, args []string) (interface{}, error) {
+		if len(args) > 2 {
+			return nil, errSyntax
+		}
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		count := int64(1)
+		if len(args) == 2 {
+			if count, err = parseInt(args[1]); err != nil || count < 0 {
+				return nil, errNotInt
+			}
+		}
+		popped := randomMembers(set.members(), count)
+		for _, m := range popped {
+			delete(set, m)
+		}
+		s.db.cleanup(args[0])
+		if len(args) == 2 {
+			return popped, nil
+		}
+		if len(popped) == 0 {
+			return nil, nil
+		}
+		return popped[0], nil
+	}},
+	"smove": {4, func(s *Server, args []string) (interface{}, error) {
+		src, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.db.getSet(args[1], false); err != nil {
+			return nil, err
+		}
+		if _, ok := src[args[2]]; !ok {
+			return int64(0), nil
+		}
+		delete(src, args[2])
+		s.db.cleanup(args[0])
+		dst, _ := s.db.getSet(args[1], true)
+		dst[args[2]] = struct{}{}
+		return int64(1), nil
+	}},
+	"sscan": {-3, func(s *Server, args []string) (interface{}, error) {
+		set, err := s.db.getSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		match, count, _, err := parseScanOptions(args[2:], false)
+		if err != nil {
+			return nil, err
+		}
+		cursor, page, err := scanPage(set.members(), args[1], count)
+		if err != nil {
+			return nil, err
+		}
+		members := []string{}
+		for _, m := range page {
+			if match == "" || globMatch(match, m) {
+				members = append(members, m)
+			}
+		}
+		return []interface{}{cursor, members}, nil
+	}},
+}
+
+func (set setValue) members() []string {
+	members := make([]string, 0, len(set))
+	for m := range set {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func intersect(a, b setValue) setValue {
+	result := setValue{}
+	for m := range a {
+		if _, ok := b[m]; ok {
+			result[m] = struct{}{}
+		}
+	}
+	return result
+}
+
+func union(a, b setValue) setValue {
+	result := setValue{}
+	for m := range a {
+		result[m] = struct{}{}
+	}
+	for m := range b {
+		result[m] = struct{}{}
+	}
+	return result
+}
+
+func difference(a, b setValue) setValue {
+	result := setValue{}
+	for m := range a {
+		if _, ok := b[m]; !ok {
+			result[m] = struct{}{}
+		}
+	}
+	return result
+}
+
+func cmdSetAlgebra(op func(a, b setValue) setValue, store bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		keys := args
+		if store {
+			keys = args[1:]
+		}
+		var result setValue
+		for i, key := range keys {
+			set, err := s.db.getSet(key, false)
+			if err != nil {
+				return nil, err
+			}
+			if set == nil {
+				set = setValue{}
+			}
+			if i == 0 {
+				result = union(set, nil)
+				continue
+			}
+			result = op(result, set)
+		}
+		if !store {
+			return result.members(), nil
+		}
+		s.db.del(args[0])
+		if len(result) > 0 {
+			s.db.set(args[0], result)
+		}
+		return int64(len(result)), nil
+	}
+}
@@ -0,0 +1,324 @@
+package upstashtest
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var stringCommands = map[string]command{
+	"get": {2, func(s *Server, args []string) (interface{}, error) {
+		v, ok, err := s.db.getString(args[0])
+		if err != nil || !ok {
+			return nil, err
+		}
+		return v, nil
+	}},
+	"set":    {-3, cmdSet},
+	"setex":  {4, cmdSetEX(time.Second)},
+	"psetex": {4, cmdSetEX(time.Millisecond)},
+	"setnx": {3, func(s *Server, args []string) (interface{}, error) {
+		if s.db.exists(args[0]) {
+			return int64(0), nil
+		}
+		s.db.set(args[0], args[1])
+		return int64(1), nil
+	}},
+	"getset": {3, func(s *Server, args []string) (interface{}, error) {
+		v, ok, err := s.db.getString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		s.db.set(args[0], args[1])
+		if !ok {
+			return nil, nil
+		}
+		return v, nil
+	}},
+	"getdel": {2, func(s *Server, args []string) (interface{}, error) {
+		v, ok, err := s.db.getString(args[0])
+		if err != nil || !ok {
+			return nil, err
+		}
+		s.db.del(args[0])
+		return v, nil
+	}},
+	"getex": {-2, cmdGetEX},
+	"getrange": {4, func(s *Server, args []string) (interface{}, error) {
+		v, _, err := s.db.getString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		from, to := normalizeRange(start, stop, len(v))
+		return v[from:to], nil
+	}},
+	"setrange": {4, func(s *Server, args []string) (interface{}, error) {
+		v, _, err := s.db.getString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := parseInt(args[1])
+		if err != nil || offset < 0 {
+			return nil, errNotInt
+		}
+		b := []byte(v)
+		if end := int(offset) + len(args[2]); end > len(b) {
+			b = append(b, make([]byte, end-len(b))...)
+		}
+		copy(b[offset:], args[2])
+		s.db.updateString(args[0], string(b))
+		return int64(len(b)), nil
+	}},
+	"strlen": {2, func(s *Server, args []string) (interface{}, error) {
+		v, _, err := s.db.getString(args[0])
+		return int64(len(v)), err
+	}},
+	"append": {3, func(s *Server, args []string) (interface{}, error) {
+		v, _, err := s.db.getString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		v += args[1]
+		s.db.updateString(args[0], v)
+		return int64(len(v)), nil
+	}},
+	"incr": {2, func(s *Server, args []string) (interface{}, error) {
+		return incrBy(s, args[0], 1)
+	}},
+	"decr": {2, func(s *Server, args []string) (interface{}, error) {
+		return incrBy(s, args[0], -1)
+	}},
+	"incrby": {3, func(s *Server, args []string) (interface{}, error) {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return incrBy(s, args[0], n)
+	}},
+	"decrby": {3, func(s *Server, args []string) (interface{}, error) {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return incrBy(s, args[0], -n)
+	}},
+	"incrbyfloat": {3, func(s *Server, args []string) (interface{}, error) {
+		v, ok, err := s.db.getString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		current := 0.0
+		if ok {
+			if current, err = parseFloat(v); err != nil {
+				return nil, err
+			}
+		}
+		increment, err := parseFloat(args[1])
+		if err != nil {
+			return nil, err
+		}
+		result := formatFloat(current + increment)
+		s.db.updateString(args[0], result)
+		return result, nil
+	}},
+	"mget": {-2, func(s *Server, args []string) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, key := range args {
+			if v, ok, err := s.db.getString(key); err == nil && ok {
+				values[i] = v
+			}
+		}
+		return values, nil
+	}},
+	"mset": {-3, func(s *Server, args []string) (interface{}, error) {
+		if len(args)%2 != 0 {
+			return nil, errArity("mset")
+		}
+		for i := 0; i < len(args); i += 2 {
+			s.db.set(args[i], args[i+1])
+		}
+		return status("OK"), nil
+	}},
+	"msetnx": {-3, func(s *Server, args []string) (interface{}, error) {
+		if len(args)%2 != 0 {
+			return nil, errArity("msetnx")
+		}
+		for i := 0; i < len(args); i += 2 {
+			if s.db.exists(args[i]) {
+				return int64(0), nil
+			}
+		}
+		for i := 0; i < len(args); i += 2 {
+			s.db.set(args[i], args[i+1])
+		}
+		return int64(1), nil
+	}},
+}
+
+func incrBy(s *Server, key string, increment int64) (interface{}, error) {
+	v, ok, err := s.db.getString(key)
+	if err != nil {
+		return nil, err
+	}
+	var current int64
+	if ok {
+		if current, err = parseInt(v); err != nil {
+			return nil, err
+		}
+	}
+	current += increment
+	s.db.updateString(key, strconv.FormatInt(current, 10))
+	return current, nil
+}
+
+func cmdSet(s *Server, args []string) (interface{}, error) {
+	key, value := args[0], args[1]
+	var (
+		nx, xx, get, keepTTL bool
+		expireAt             time.Time
+	)
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToLower(args[i])
+		switch opt {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "get":
+			get = true
+		case "keepttl":
+			keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if i+1 >= len(args) || !expireAt.IsZero() {
+				return nil, errSyntax
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if n <= 0 {
+				return nil, errInvalidExpire("set")
+			}
+			expireAt = expiry(opt, n)
+			i++
+		default:
+			return nil, errSyntax
+		}
+	}
+	if nx && xx {
+		return nil, errSyntax
+	}
+
+	old, existed, err := s.db.getString(key)
+	if get && err != nil {
+		return nil, err
+	}
+	existed = existed || s.db.exists(key)
+	if (nx && existed) || (xx && !existed) {
+		if get && existed {
+			return old, nil
+		}
+		return nil, nil
+	}
+
+	var ttl time.Time
+	if keepTTL {
+		if e := s.db.get(key); e != nil {
+			ttl = e.expireAt
+		}
+	}
+	s.db.set(key, value)
+	if !expireAt.IsZero() {
+		ttl = expireAt
+	}
+	s.db.get(key).expireAt = ttl
+
+	if get {
+		if !existed {
+			return nil, nil
+		}
+		return old, nil
+	}
+	return status("OK"), nil
+}
+
+func cmdSetEX(unit time.Duration) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return nil, errInvalidExpire("setex")
+		}
+		s.db.set(args[0], args[2])
+		s.db.get(args[0]).expireAt = time.Now().Add(time.Duration(n) * unit)
+		return status("OK"), nil
+	}
+}
+
+func cmdGetEX(s *Server, args []string) (interface{}, error) {
+	key := args[0]
+	var (
+		expireAt time.Time
+		persist  bool
+	)
+	for i := 1; i < len(args); i++ {
+		opt := strings.ToLower(args[i])
+		switch opt {
+		case "persist":
+			persist = true
+		case "ex", "px", "exat", "pxat":
+			if i+1 >= len(args) || !expireAt.IsZero() {
+				return nil, errSyntax
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if n <= 0 {
+				return nil, errInvalidExpire("getex")
+			}
+			expireAt = expiry(opt, n)
+			i++
+		default:
+			return nil, errSyntax
+		}
+	}
+	if persist && !expireAt.IsZero() {
+		return nil, errSyntax
+	}
+
+	v, ok, err := s.db.getString(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if persist {
+		s.db.get(key).expireAt = time.Time{}
+	} else if !expireAt.IsZero() {
+		s.db.get(key).expireAt = expireAt
+	}
+	return v, nil
+}
+
+// Compute the expiry time for the EX, PX, EXAT and PXAT options.
+func expiry(option string, n int64) time.Time {
+	switch option {
+	case "ex":
+		return time.Now().Add(time.Duration(n) * time.Second)
+	case "px":
+		return time.Now().Add(time.Duration(n) * time.Millisecond)
+	case "exat":
+		return time.Unix(n, 0)
+	default:
+		return time.Unix(0, n*int64(time.Millisecond))
+	}
+}
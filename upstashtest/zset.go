@@ -0,0 +1,610 @@
+package upstashtest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	errMinMaxNotFloat = errors.New("ERR min or max is not a float")
+	errMinMaxNotLex   = errors.New("ERR min or max not valid string range item")
+)
+
+var zsetCommands = map[string]command{
+	"zadd": {-4, cmdZAdd},
+	"zcard": {2, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		return int64(len(z)), err
+	}},
+	"zcount": {4, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		min, err := parseScoreBound(args[1])
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseScoreBound(args[2])
+		if err != nil {
+			return nil, err
+		}
+		var n int64
+		for _, score := range z {
+			if min.below(score) && max.above(score) {
+				n++
+			}
+		}
+		return n, nil
+	}},
+	"zincrby": {4, func(s *Server, args []string) (interface{}, error) {
+		increment, err := parseFloat(args[1])
+		if err != nil {
+			return nil, err
+		}
+		z, err := s.db.getZSet(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		z[args[2]] += increment
+		return formatFloat(z[args[2]]), nil
+	}},
+	"zscore": {3, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		score, ok := z[args[1]]
+		if !ok {
+			return nil, nil
+		}
+		return formatFloat(score), nil
+	}},
+	"zmscore": {-3, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		scores := make([]interface{}, len(args)-1)
+		for i, m := range args[1:] {
+			if score, ok := z[m]; ok {
+				scores[i] = formatFloat(score)
+			}
+		}
+		return scores, nil
+	}},
+	"zrank":    {3, cmdZRank(false)},
+	"zrevrank": {3, cmdZRank(true)},
+	"zrem": {-3, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		var removed int64
+		for _, m := range args[1:] {
+			if _, ok := z[m]; ok {
+				delete(z, m)
+				removed++
+			}
+		}
+		s.db.cleanup(args[0])
+		return removed, nil
+	}},
+	"zremrangebyrank": {4, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		members := z.sorted()
+		from, to := normalizeRange(start, stop, len(members))
+		for _, m := range members[from:to] {
+			delete(z, m)
+		}
+		s.db.cleanup(args[0])
+		return int64(to - from), nil
+	}},
+	"zremrangebyscore": {4, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		min, err := parseScoreBound(args[1])
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseScoreBound(args[2])
+		if err != nil {
+			return nil, err
+		}
+		var removed int64
+		for m, score := range z {
+			if min.below(score) && max.above(score) {
+				delete(z, m)
+				removed++
+			}
+		}
+		s.db.cleanup(args[0])
+		return removed, nil
+	}},
+	"zrange":      {-4, cmdZRange},
+	"zpopmin":     {-2, cmdZPop(false)},
+	"zpopmax":     {-2, cmdZPop(true)},
+	"zunionstore": {-4, cmdZStore(true)},
+	"zinterstore": {-4, cmdZStore(false)},
+	"zscan": {-3, func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		match, count, _, err := parseScanOptions(args[2:], false)
+		if err != nil {
+			return nil, err
+		}
+		cursor, page, err := scanPage(z.sorted(), args[1], count)
+		if err != nil {
+			return nil, err
+		}
+		values := []string{}
+		for _, m := range page {
+			if match == "" || globMatch(match, m) {
+				values = append(values, m, formatFloat(z[m]))
+			}
+		}
+		return []interface{}{cursor, values}, nil
+	}},
+}
+
+// Members ordered by score, then lexicographically.
+func (z zsetValue) sorted() []string {
+	members := make([]string, 0, len(z))
+	for m := range z {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if z[a] != z[b] {
+			return z[a] < z[b]
+		}
+		return a < b
+	})
+	return members
+}
+
+func cmdZAdd(s *Server, args []string) (interface{}, error) {
+	var nx, xx, gt, lt, ch, incr bool
+	i := 1
+flags:
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "gt":
+			gt = true
+		case "lt":
+			lt = true
+		case "ch":
+			ch = true
+		case "incr":
+			incr = true
+		default:
+			break flags
+		}
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return nil, errSyntax
+	}
+	if nx && xx {
+		return nil, errors.New("ERR XX and NX options at the same time are not compatible")
+	}
+	if (gt && lt) || (nx && (gt || lt)) {
+		return nil, errors.New("ERR GT, LT, and/or NX options at the same time are not compatible")
+	}
+	if incr && len(pairs) != 2 {
+		return nil, errors.New("ERR INCR option supports a single increment-element pair")
+	}
+	scores := make([]float64, len(pairs)/2)
+	for j := range scores {
+		score, err := parseFloat(pairs[j*2])
+		if err != nil {
+			return nil, err
+		}
+		scores[j] = score
+	}
+
+	z, err := s.db.getZSet(args[0], false)
+	if err != nil {
+		return nil, err
+	}
+	if z == nil {
+		if xx {
+			if incr {
+				return nil, nil
+			}
+			return int64(0), nil
+		}
+		z, _ = s.db.getZSet(args[0], true)
+	}
+
+	var added, changed int64
+	for j, score := range scores {
+		member := pairs[j*2+1]
+		current, exists := z[member]
+		if (nx && exists) || (xx && !exists) {
+			if incr {
+				return nil, nil
+			}
+			continue
+		}
+		if incr && exists {
+			score += current
+		}
+		if exists && ((gt && score <= current) || (lt && score >= current)) {
+			if incr {
+				return nil, nil
+			}
+			continue
+		}
+		z[member] = score
+		if !exists {
+			added++
+			changed++
+		} else if score != current {
+			changed++
+		}
+		if incr {
+			return formatFloat(score), nil
+		}
+	}
+	s.db.cleanup(args[0])
+	if ch {
+		return changed, nil
+	}
+	return added, nil
+}
+
+func cmdZRank(reverse bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := z[args[1]]; !ok {
+			return nil, nil
+		}
+		members := z.sorted()
+		for i, m := range members {
+			if m == args[1] {
+				if reverse {
+					return int64(len(members) - 1 - i), nil
+				}
+				return int64(i), nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func cmdZRange(s *Server, args []string) (interface{}, error) {
+	var (
+		byScore, byLex, rev, withScores, limit bool
+		offset, count                          int64
+	)
+	for i := 3; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "byscore":
+			byScore = true
+		case "bylex":
+			byLex = true
+		case "rev":
+			rev = true
+		case "withscores":
+			withScores = true
+		case "limit":
+			if i+2 >= len(args) {
+				return nil, errSyntax
+			}
+			var err error
+			if offset, err = parseInt(args[i+1]); err != nil {
+				return nil, err
+			}
+			if count, err = parseInt(args[i+2]); err != nil {
+				return nil, err
+			}
+			limit = true
+			i += 2
+		default:
+			return nil, errSyntax
+		}
+	}
+	if (byScore && byLex) || (limit && !byScore && !byLex) || (withScores && byLex) {
+		return nil, errSyntax
+	}
+
+	z, err := s.db.getZSet(args[0], false)
+	if err != nil {
+		return nil, err
+	}
+	members := z.sorted()
+	if rev {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+
+	min, max := args[1], args[2]
+	if rev {
+		min, max = max, min
+	}
+	var selected []string
+	switch {
+	case byScore:
+		lo, err := parseScoreBound(min)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseScoreBound(max)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if lo.below(z[m]) && hi.above(z[m]) {
+				selected = append(selected, m)
+			}
+		}
+	case byLex:
+		lo, err := parseLexBound(min)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseLexBound(max)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if lo.below(m) && hi.above(m) {
+				selected = append(selected, m)
+			}
+		}
+	default:
+		start, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		from, to := normalizeRange(start, stop, len(members))
+		selected = members[from:to]
+	}
+
+	if limit {
+		if offset < 0 || offset >= int64(len(selected)) {
+			selected = nil
+		} else {
+			selected = selected[offset:]
+			if count >= 0 && count < int64(len(selected)) {
+				selected = selected[:count]
+			}
+		}
+	}
+
+	result := []string{}
+	for _, m := range selected {
+		result = append(result, m)
+		if withScores {
+			result = append(result, formatFloat(z[m]))
+		}
+	}
+	return result, nil
+}
+
+func cmdZPop(max bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		if len(args) > 2 {
+			return nil, errSyntax
+		}
+		count := int64(1)
+		if len(args) == 2 {
+			var err error
+			if count, err = parseInt(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		z, err := s.db.getZSet(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		members := z.sorted()
+		if max {
+			for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+				members[i], members[j] = members[j], members[i]
+			}
+		}
+		if count < int64(len(members)) {
+			members = members[:count]
+		}
+		result := []string{}
+		for _, m := range members {
+			result = append(result, m, formatFloat(z[m]))
+			delete(z, m)
+		}
+		s.db.cleanup(args[0])
+		return result, nil
+	}
+}
+
+func cmdZStore(union bool) func(s *Server, args []string) (interface{}, error) {
+	return func(s *Server, args []string) (interface{}, error) {
+		numKeys, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if numKeys < 1 || int(numKeys) > len(args)-2 {
+			return nil, errSyntax
+		}
+		keys := args[2 : 2+numKeys]
+		weights := make([]float64, len(keys))
+		for i := range weights {
+			weights[i] = 1
+		}
+		aggregate := "sum"
+		for i := 2 + int(numKeys); i < len(args); i++ {
+			switch strings.ToLower(args[i]) {
+			case "weights":
+				if i+len(keys) >= len(args) {
+					return nil, errSyntax
+				}
+				for j := range keys {
+					w, err := parseFloat(args[i+1+j])
+					if err != nil {
+						return nil, errors.New("ERR weight value is not a float")
+					}
+					weights[j] = w
+				}
+				i += len(keys)
+			case "aggregate":
+				if i+1 >= len(args) {
+					return nil, errSyntax
+				}
+				aggregate = strings.ToLower(args[i+1])
+				if aggregate != "sum" && aggregate != "min" && aggregate != "max" {
+					return nil, errSyntax
+				}
+				i++
+			default:
+				return nil, errSyntax
+			}
+		}
+
+		result := zsetValue{}
+		seen := map[string]int{}
+		for i, key := range keys {
+			z, err := s.db.getZSet(key, false)
+			if err != nil {
+				return nil, err
+			}
+			for m, score := range z {
+				score *= weights[i]
+				if math.IsNaN(score) {
+					score = 0
+				}
+				current, ok := result[m]
+				seen[m]++
+				if !ok {
+					result[m] = score
+					continue
+				}
+				switch aggregate {
+				case "sum":
+					result[m] = current + score
+				case "min":
+					result[m] = math.Min(current, score)
+				case "max":
+					result[m] = math.Max(current, score)
+				}
+			}
+		}
+		if !union {
+			for m, n := range seen {
+				if n != len(keys) {
+					delete(result, m)
+				}
+			}
+		}
+		s.db.del(args[0])
+		if len(result) > 0 {
+			s.db.set(args[0], result)
+		}
+		return int64(len(result)), nil
+	}
+}
+
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+func parseScoreBound(s string) (scoreBound, error) {
+	b := scoreBound{}
+	if strings.HasPrefix(s, "(") {
+		b.exclusive = true
+		s = s[1:]
+	}
+	v, err := parseFloat(s)
+	if err != nil {
+		return b, errMinMaxNotFloat
+	}
+	b.value = v
+	return b, nil
+}
+
+// Whether score is above this lower bound.
+func (b scoreBound) below(score float64) bool {
+	if b.exclusive {
+		return b.value < score
+	}
+	return b.value <= score
+}
+
+// Whether score is below this upper bound.
+func (b scoreBound) above(score float64) bool {
+	if b.exclusive {
+		return score < b.value
+	}
+	return score <= b.value
+}
+
+type lexBound struct {
+	value     string
+	exclusive bool
+	// -1 for `-`, 1 for `+`
+	infinite int
+}
+
+func parseLexBound(s string) (lexBound, error) {
+	switch {
+	case s == "-":
+		return lexBound{infinite: -1}, nil
+	case s == "+":
+		return lexBound{infinite: 1}, nil
+	case strings.HasPrefix(s, "["):
+		return lexBound{value: s[1:]}, nil
+	case strings.HasPrefix(s, "("):
+		return lexBound{value: s[1:], exclusive: true}, nil
+	}
+	return lexBound{}, errMinMaxNotLex
+}
+
+func (b lexBound) below(member string) bool {
+	switch {
+	case b.infinite != 0:
+		return b.infinite < 0
+	case b.exclusive:
+		return b.value < member
+	}
+	return b.value <= member
+}
+
+func (b lexBound) above(member string) bool {
+	switch {
+	case b.infinite != 0:
+		return b.infinite > 0
+	case b.exclusive:
+		return member < b.value
+	}
+	return member <= b.value
+}